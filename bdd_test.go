@@ -1,17 +1,141 @@
 package errors
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	stderrors "errors"
 	"fmt"
+	"html/template"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/cucumber/godog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
+type healthTestServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	err error
+}
+
+// Check fails with the error until it has been called more than serverFailures times; always when zero
+func (s healthTestServer) Check(context.Context, *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if calls := serverCalls.Add(1); serverFailures > 0 && calls > serverFailures {
+		return &grpc_health_v1.HealthCheckResponse{}, nil
+	}
+	return nil, s.err
+}
+
+func (s healthTestServer) Watch(_ *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	serverCalls.Add(1)
+	if err := stream.Send(&grpc_health_v1.HealthCheckResponse{}); err != nil {
+		return err
+	}
+	return s.err
+}
+
+// callTestServer returns the error received by a client after a server handler returns handlerErr
+//
+// Without the client interceptors ReceiveGRPCError is called on the error directly.
+func callTestServer(handlerErr error, streaming bool, serverOpts []Option, withInterceptors bool) error {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(serverOpts...)),
+		grpc.StreamInterceptor(StreamServerInterceptor(serverOpts...)),
+	)
+	grpc_health_v1.RegisterHealthServer(srv, healthTestServer{err: handlerErr})
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	receive := func(err error) error { return ReceiveGRPCError(err, receiveOptions()...) }
+	dialOpts := []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	switch {
+	case clientRetries != nil:
+		receive = func(err error) error { return err }
+		dialOpts = append(dialOpts,
+			grpc.WithUnaryInterceptor(UnaryClientRetryInterceptor(*clientRetries, receiveOptions()...)),
+			grpc.WithStreamInterceptor(StreamClientRetryInterceptor(*clientRetries, receiveOptions()...)),
+		)
+	case withInterceptors:
+		receive = func(err error) error { return err }
+		dialOpts = append(dialOpts,
+			grpc.WithUnaryInterceptor(UnaryClientInterceptor(receiveOptions()...)),
+			grpc.WithStreamInterceptor(StreamClientInterceptor(receiveOptions()...)),
+		)
+	}
+
+	ctx := context.Background()
+	if callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, callTimeout)
+		defer cancel()
+	}
+
+	cc, err := grpc.NewClient("passthrough:///bufconn", dialOpts...)
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	client := grpc_health_v1.NewHealthClient(cc)
+	if !streaming {
+		_, err = client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		return receive(err)
+	}
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return receive(err)
+	}
+	if _, err = stream.Recv(); err != nil {
+		return fmt.Errorf("expected the first message to be received: %w", err)
+	}
+	_, err = stream.Recv()
+	return receive(err)
+}
+
+type resourceTestError struct {
+	resourceType string
+	name         string
+}
+
+func (e resourceTestError) Error() string {
+	return fmt.Sprintf("%s %s", e.resourceType, e.name)
+}
+
+func init() {
+	RegisterDetail(
+		func(e resourceTestError) *errdetails.ResourceInfo {
+			return &errdetails.ResourceInfo{ResourceType: e.resourceType, ResourceName: e.name}
+		},
+		func(d *errdetails.ResourceInfo) resourceTestError {
+			return resourceTestError{resourceType: d.GetResourceType(), name: d.GetResourceName()}
+		},
+	)
+}
+
 type typeTestError struct {
 	t string
 	e error
@@ -212,20 +336,56 @@ func convertErrNameToError(errName string) Error {
 		return ErrBadRequest
 	case "ErrUnauthorized":
 		return ErrUnauthorized
+	case "ErrPaymentRequired":
+		return ErrPaymentRequired
 	case "ErrForbidden":
 		return ErrForbidden
 	case "ErrMethodNotAllowed":
 		return ErrMethodNotAllowed
+	case "ErrNotAcceptable":
+		return ErrNotAcceptable
+	case "ErrProxyAuthRequired":
+		return ErrProxyAuthRequired
 	case "ErrRequestTimeout":
 		return ErrRequestTimeout
 	case "ErrConflict":
 		return ErrConflict
+	case "ErrGone":
+		return ErrGone
+	case "ErrLengthRequired":
+		return ErrLengthRequired
+	case "ErrPreconditionFailed":
+		return ErrPreconditionFailed
+	case "ErrPayloadTooLarge":
+		return ErrPayloadTooLarge
+	case "ErrURITooLong":
+		return ErrURITooLong
+	case "ErrUnsupportedMediaType":
+		return ErrUnsupportedMediaType
+	case "ErrRangeNotSatisfiable":
+		return ErrRangeNotSatisfiable
+	case "ErrExpectationFailed":
+		return ErrExpectationFailed
 	case "ErrImATeapot":
 		return ErrImATeapot
+	case "ErrMisdirectedRequest":
+		return ErrMisdirectedRequest
 	case "ErrUnprocessableEntity":
 		return ErrUnprocessableEntity
+	case "ErrLocked":
+		return ErrLocked
+	case "ErrFailedDependency":
+		return ErrFailedDependency
+	case "ErrTooEarly":
+		return ErrTooEarly
+	case "ErrUpgradeRequired":
+		return ErrUpgradeRequired
+	case "ErrPreconditionRequired":
+		return ErrPreconditionRequired
 	case "ErrTooManyRequests":
 		return ErrTooManyRequests
+	case "ErrRequestHeaderFieldsTooLarge":
+		return ErrRequestHeaderFieldsTooLarge
 	case "ErrUnavailableForLegalReasons":
 		return ErrUnavailableForLegalReasons
 	case "ErrInternalServerError":
@@ -238,6 +398,16 @@ func convertErrNameToError(errName string) Error {
 		return ErrServiceUnavailable
 	case "ErrGatewayTimeout":
 		return ErrGatewayTimeout
+	case "ErrHTTPVersionNotSupported":
+		return ErrHTTPVersionNotSupported
+	case "ErrVariantAlsoNegotiates":
+		return ErrVariantAlsoNegotiates
+	case "ErrInsufficientStorage":
+		return ErrInsufficientStorage
+	case "ErrLoopDetected":
+		return ErrLoopDetected
+	case "ErrNetworkAuthenticationRequired":
+		return ErrNetworkAuthenticationRequired
 	default:
 		return ErrUnknown
 	}
@@ -256,6 +426,7 @@ func TestMain(m *testing.M) {
 		Format:   format,
 		Paths:    []string{"features"},
 		NoColors: true,
+		Strict:   true,
 	}
 
 	status := godog.TestSuite{
@@ -275,6 +446,42 @@ func TestMain(m *testing.M) {
 
 // var expectedMessage string
 var expectedError error
+var serverOptions []Option
+var serverLogged []error
+var clientInterceptors bool
+var clientOptions []Option
+var convertedStatus *status.Status
+var clientRetries *RetryPolicy
+var response *httptest.ResponseRecorder
+var received *http.Response
+var renderOptions []Option
+var recovered interface{}
+var graphqlResponse []byte
+var callTimeout time.Duration
+var callDuration time.Duration
+var serverCalls atomic.Int32
+var serverFailures int32
+var clientTrust *TrustPolicy
+var mismatches []Mismatch
+
+// receiveOptions returns the options used by the client including any trust policy
+func receiveOptions() []Option {
+	if clientTrust == nil {
+		return clientOptions
+	}
+	return append([]Option{WithTrustPolicy(*clientTrust)}, clientOptions...)
+}
+
+func trustPolicy() *TrustPolicy {
+	if clientTrust == nil {
+		clientTrust = &TrustPolicy{
+			OnMismatch: func(mismatch Mismatch) {
+				mismatches = append(mismatches, mismatch)
+			},
+		}
+	}
+	return clientTrust
+}
 
 func theErrorDoesNotImplementCoderInterfaces() error {
 	expectedError = fmt.Errorf("%s", expectedError)
@@ -325,6 +532,10 @@ func wrappedWithTheMessage(message string) error {
 	return nil
 }
 
+func wrappedWithTheMultiLineMessage(doc *godog.DocString) error {
+	return wrappedWithTheMessage(doc.Content)
+}
+
 func wrappedWithTheError(errName, message string) error {
 	err := convertErrNameToError(errName)
 	expectedError = err.Wrap(expectedError, message)
@@ -353,6 +564,13 @@ func theHTTPStatusIs(httpStatus string) error {
 	return nil
 }
 
+func theHTTPCodeIs(httpCode int) error {
+	if got := HTTPCode(expectedError); got != httpCode {
+		return fmt.Errorf("expected HTTP code to be `%d` but got `%d`", httpCode, got)
+	}
+	return nil
+}
+
 func theTypeCodeIs(typeCode string) error {
 	got := TypeCode(expectedError)
 	if got != typeCode {
@@ -376,15 +594,1280 @@ func theErrorIsA(errName string) error {
 	return nil
 }
 
-func InitializeTestSuite(ctx *godog.TestSuiteContext) {
-	ctx.BeforeSuite(func() {
-		expectedError = ErrUnknown
+func theHelpLinkIsAttached(url string) error {
+	expectedError = WithHelp(expectedError, "help", url)
+	return nil
+}
+
+func theHelpLinkIsRegisteredFor(url, errName string) error {
+	RegisterHelp(convertErrNameToError(errName), "help", url)
+	return nil
+}
+
+func theHelpLinksInclude(url string) error {
+	for _, link := range HelpLinks(expectedError) {
+		if link.URL == url {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected help links to include `%s` but got `%v`", url, HelpLinks(expectedError))
+}
+
+func theErrorHasNoHelpLinks() error {
+	if links := HelpLinks(expectedError); len(links) != 0 {
+		return fmt.Errorf("expected no help links but got `%v`", links)
+	}
+	return nil
+}
+
+func theLocalizedMessageIsAttachedFor(message, locale string) error {
+	expectedError = WithLocalizedMessage(expectedError, locale, message)
+	return nil
+}
+
+func theErrorIsSentOverGRPCFromWithTheDefaultType(origin, errName string) error {
+	expectedError = ReceiveGRPCError(SendGRPCError(expectedError, WithOrigin(origin), WithDefaultType(convertErrNameToError(errName))))
+	return nil
+}
+
+func theErrorIsSentOverGRPCWithATimestamp() error {
+	grpcErr := SendGRPCError(expectedError, WithTimestamp())
+	grpcErr = ReceiveGRPCError(grpcErr)
+	expectedError = grpcErr
+	return nil
+}
+
+func theErrorIsSentOverGRPCWithTheCauseChain() error {
+	grpcErr := SendGRPCError(expectedError, WithCauseChain())
+	grpcErr = ReceiveGRPCError(grpcErr)
+	expectedError = grpcErr
+	return nil
+}
+
+func theErrorIsSentOverGRPCToAClientAccepting(acceptLanguage string) error {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", acceptLanguage))
+	grpcErr := SendGRPCError(expectedError, WithIncomingContext(ctx))
+	grpcErr = ReceiveGRPCError(grpcErr)
+	expectedError = grpcErr
+	return nil
+}
+
+func theLocalizedMessageIs(message string) error {
+	localized, ok := Localized(expectedError)
+	if !ok {
+		return fmt.Errorf("expected localized message to be `%s` but there was none", message)
+	}
+	if localized.Message != message {
+		return fmt.Errorf("expected localized message to be `%s` but got `%s`", message, localized.Message)
+	}
+	return nil
+}
+
+func debugInfoIsEnabled() error {
+	EnableDebugInfo(true)
+	return nil
+}
+
+func strictMatchingIsEnabled() error {
+	SetMatchMode(MatchStrict)
+	return nil
+}
+
+func theClientMatchesStrictly() error {
+	clientOptions = append(clientOptions, WithMatchMode(MatchStrict))
+	return nil
+}
+
+func theErrorIsSentOverGRPCAndReceivedWithStrictMatching() error {
+	expectedError = ReceiveGRPCError(SendGRPCError(expectedError), WithMatchMode(MatchStrict))
+	return nil
+}
+
+func aStatusWasSentWithAnErrorTypeClaiming(grpcCode string, httpCode int, claimedCode string) error {
+	s, err := status.New(convertGRPCStringToCode(grpcCode), "forged error").WithDetails(&ErrorType{
+		TypeCode: "FORGED",
+		HTTPCode: int64(httpCode),
+		GRPCCode: int64(convertGRPCStringToCode(claimedCode)),
+		Message:  "forged error",
+	})
+	if err != nil {
+		return err
+	}
+	expectedError = s.Err()
+	return nil
+}
+
+func aStatusErrorWithTheMessage(grpcCode, message string) error {
+	expectedError = status.Error(convertGRPCStringToCode(grpcCode), message)
+	return nil
+}
+
+func aStatusWasSentWithoutDetails(grpcCode string) error {
+	expectedError = status.New(convertGRPCStringToCode(grpcCode), "plain status").Err()
+	return nil
+}
+
+func aStatusWasSentWithARetryInfoOf(grpcCode, delay string) error {
+	d, err := time.ParseDuration(delay)
+	if err != nil {
+		return err
+	}
+	s, err := status.New(convertGRPCStringToCode(grpcCode), "retry later").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(d),
 	})
+	if err != nil {
+		return err
+	}
+	expectedError = s.Err()
+	return nil
 }
 
-func InitializeScenario(ctx *godog.ScenarioContext) {
-	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
-		expectedError = stderrors.New("test error")
+func anUpstreamServiceSentTheErrorWithAnErrorInfoAndAQuotaFailure(origin string) error {
+	s := ToStatus(expectedError, WithOrigin(origin), WithDetailEncoder(func(err error) proto.Message {
+		return &errdetails.ErrorInfo{Reason: TypeCode(err), Domain: "example.com"}
+	}))
+	s, err := s.WithDetails(&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{Subject: "user:42"}}})
+	if err != nil {
+		return err
+	}
+	expectedError = s.Err()
+	return nil
+}
+
+func theErrorIsRelayedOverGRPCBy(origin string) error {
+	relayed := SendGRPCError(ReceiveGRPCError(expectedError), WithOrigin(origin))
+	convertedStatus = status.Convert(relayed)
+	expectedError = ReceiveGRPCError(relayed)
+	return nil
+}
+
+func theStatusHasAQuotaFailure() error {
+	for _, detail := range convertedStatus.Details() {
+		if _, ok := detail.(*errdetails.QuotaFailure); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected status to have a QuotaFailure")
+}
+
+func theOriginIs(origin string) error {
+	if got := Origin(expectedError); got != origin {
+		return fmt.Errorf("expected origin to be `%s` but got `%s`", origin, got)
+	}
+	return nil
+}
+
+func theClientHandlesHTTPCodes(policy string) error {
+	switch policy {
+	case "rejects":
+		trustPolicy().HTTPCodes = RejectHTTPCode
+	case "clamps":
+		trustPolicy().HTTPCodes = ClampHTTPCode
+	default:
+		return fmt.Errorf("unknown HTTP code policy `%s`", policy)
+	}
+	return nil
+}
+
+func theClientVerifiesGRPCCodes() error {
+	trustPolicy().VerifyGRPCCode = true
+	return nil
+}
+
+func theClientDoesNotAcceptDetailsFrom(service string) error {
+	trustPolicy().AcceptDetails = func(target, method string) bool {
+		return !strings.HasPrefix(method, "/"+service+"/")
+	}
+	return nil
+}
+
+func detailsAreNotAcceptedFromAnyServer() error {
+	SetTrustPolicy(TrustPolicy{AcceptDetails: func(target, method string) bool { return false }})
+	return nil
+}
+
+func aMismatchWasReported(reason string) error {
+	for _, mismatch := range mismatches {
+		if mismatch.Reason == reason {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected a `%s` mismatch but got `%v`", reason, mismatches)
+}
+
+func aMismatchWasReportedFor(reason, method string) error {
+	for _, mismatch := range mismatches {
+		if mismatch.Reason == reason && mismatch.Method == method && mismatch.Target != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected a `%s` mismatch for `%s` but got `%v`", reason, method, mismatches)
+}
+
+func noMismatchWasReported() error {
+	if len(mismatches) != 0 {
+		return fmt.Errorf("expected no mismatches but got `%v`", mismatches)
+	}
+	return nil
+}
+
+func wrappedWithAMessageOfBytes(size int) error {
+	expectedError = Wrap(expectedError, strings.Repeat("x", size))
+	return nil
+}
+
+func wrappedWithANonASCIIMessageOfBytes(size int) error {
+	expectedError = Wrap(expectedError, strings.Repeat("é", size/len("é")))
+	return nil
+}
+
+func theErrorHasThePublicMessageOfBytes(size int) error {
+	expectedError = WithPublicMessage(expectedError, strings.Repeat("x", size))
+	return nil
+}
+
+func theErrorHasTheFieldWithAValueOfBytes(key string, size int) error {
+	expectedError = WithField(expectedError, key, strings.Repeat("x", size))
+	return nil
+}
+
+func theStatusBudgetIsBytes(budget int) error {
+	SetStatusBudget(budget)
+	return nil
+}
+
+func theErrorIsSentOverGRPCWithABudgetOfBytes(budget int) error {
+	expectedError = ReceiveGRPCError(SendGRPCError(expectedError, WithStatusBudget(budget)))
+	return nil
+}
+
+func theErrorIsTruncated() error {
+	if !Truncated(expectedError) {
+		return fmt.Errorf("expected error to be truncated")
+	}
+	return nil
+}
+
+func theErrorIsNotTruncated() error {
+	if Truncated(expectedError) {
+		return fmt.Errorf("expected error to not be truncated")
+	}
+	return nil
+}
+
+func theErrorMessageIsShorterThanBytes(size int) error {
+	if got := len(expectedError.Error()); got >= size {
+		return fmt.Errorf("expected error message to be shorter than `%d` bytes but it was `%d`", size, got)
+	}
+	return nil
+}
+
+func thePublicMessageIsShorterThanBytes(size int) error {
+	if got := len(PublicMessage(expectedError)); got >= size {
+		return fmt.Errorf("expected public message to be shorter than `%d` bytes but it was `%d`", size, got)
+	}
+	return nil
+}
+
+func thePublicMessageEndsWith(suffix string) error {
+	if got := PublicMessage(expectedError); !strings.HasSuffix(got, suffix) {
+		return fmt.Errorf("expected public message to end with `%s` but got `%s`", suffix, got)
+	}
+	return nil
+}
+
+func theErrorMessageEndsWith(suffix string) error {
+	if !strings.HasSuffix(expectedError.Error(), suffix) {
+		return fmt.Errorf("expected error message to end with `%s` but got `%s`", suffix, expectedError.Error())
+	}
+	return nil
+}
+
+func theErrorIsConvertedToAStatus() error {
+	convertedStatus = ToStatus(expectedError)
+	return nil
+}
+
+func theErrorIsConvertedToAStatusWithTheDefaultType(errName string) error {
+	convertedStatus = ToStatus(expectedError, WithDefaultType(convertErrNameToError(errName)))
+	return nil
+}
+
+func theErrorIsConvertedToAStatusWithMasking() error {
+	convertedStatus = ToStatus(expectedError, WithMasking())
+	return nil
+}
+
+func theErrorIsConvertedToAStatusWithAnErrorInfoEncoder() error {
+	convertedStatus = ToStatus(expectedError, WithDetailEncoder(func(err error) proto.Message {
+		return &errdetails.ErrorInfo{Reason: TypeCode(err), Domain: "example.com"}
+	}))
+	return nil
+}
+
+func anOKStatusIsConverted() error {
+	convertedStatus = status.New(codes.OK, "")
+	return nil
+}
+
+func theStatusIsConvertedToAnError() error {
+	expectedError = FromStatus(convertedStatus)
+	return nil
+}
+
+func theStatusCodeIs(grpcCode string) error {
+	if got := convertedStatus.Code().String(); got != grpcCode {
+		return fmt.Errorf("expected status code to be `%s` but got `%s`", grpcCode, got)
+	}
+	return nil
+}
+
+func theStatusMessageIs(message string) error {
+	if got := convertedStatus.Message(); got != message {
+		return fmt.Errorf("expected status message to be `%s` but got `%s`", message, got)
+	}
+	return nil
+}
+
+func theStatusHasAnErrorInfoWithTheReason(reason string) error {
+	for _, detail := range convertedStatus.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() == reason {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected status to have an ErrorInfo with the reason `%s`", reason)
+}
+
+func theStatusIsNotAnError() error {
+	if expectedError != nil {
+		return fmt.Errorf("expected error to be nil but got `%v`", expectedError)
+	}
+	return nil
+}
+
+func theClientRetriesUpToTimes(attempts int) error {
+	clientRetries = &RetryPolicy{MaxAttempts: attempts, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	return nil
+}
+
+func theCallTimesOutAfter(timeout string) error {
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return err
+	}
+	callTimeout = d
+	return nil
+}
+
+func theHandlerSucceedsAfterFailures(failures int) error {
+	serverFailures = int32(failures)
+	return nil
+}
+
+func theCallSucceeded() error {
+	if expectedError != nil {
+		return fmt.Errorf("expected the call to succeed but got `%v`", expectedError)
+	}
+	return nil
+}
+
+func theHandlerWasCalledTimes(calls int) error {
+	if got := serverCalls.Load(); got != int32(calls) {
+		return fmt.Errorf("expected the handler to be called `%d` times but it was called `%d` times", calls, got)
+	}
+	return nil
+}
+
+func theCallTookAtLeast(duration string) error {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return err
+	}
+	if callDuration < d {
+		return fmt.Errorf("expected the call to take at least `%s` but it took `%s`", d, callDuration)
+	}
+	return nil
+}
+
+func theCallTookLessThan(duration string) error {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return err
+	}
+	if callDuration >= d {
+		return fmt.Errorf("expected the call to take less than `%s` but it took `%s`", d, callDuration)
+	}
+	return nil
+}
+
+func theErrorIsWrittenAsAProblem() error {
+	response = httptest.NewRecorder()
+	WriteProblem(response, httptest.NewRequest(http.MethodGet, "/", nil), expectedError)
+	return nil
+}
+
+func theErrorIsWrittenAsAProblemToAClientAccepting(acceptLanguage string) error {
+	response = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", acceptLanguage)
+	WriteProblem(response, r, expectedError)
+	return nil
+}
+
+func theErrorIsReturnedByAnHTTPHandler() error {
+	response = httptest.NewRecorder()
+	handler := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return expectedError
+	}, serverOptions...)
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/", nil))
+	return nil
+}
+
+func theErrorIsReturnedByAnHTTPHandlerAfterItWroteTheStatus(code int) error {
+	response = httptest.NewRecorder()
+	handler := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(code)
+		return expectedError
+	}, serverOptions...)
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/", nil))
+	return nil
+}
+
+func theErrorIsReturnedByAHandlerFunc() error {
+	response = httptest.NewRecorder()
+	var handler http.Handler = HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return expectedError
+	})
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/", nil))
+	return nil
+}
+
+func theErrorIsRenderedForAClientAccepting(accept string) error {
+	response = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	handler := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return expectedError
+	}, WithRenderer(NegotiatedRenderer(renderOptions...)))
+	handler.ServeHTTP(response, r)
+	return nil
+}
+
+func theRendererWritesAs(mediaType, body string) error {
+	renderOptions = append(renderOptions, WithMediaType(mediaType, func(w io.Writer, p *Problem) error {
+		_, err := fmt.Fprintf(w, body, p.TypeCode())
+		return err
+	}))
+	return nil
+}
+
+func theRendererUsesTheHTMLTemplate(text string) error {
+	t, err := template.New("custom").Parse(text)
+	if err != nil {
+		return err
+	}
+	renderOptions = append(renderOptions, WithHTMLTemplate(t))
+	return nil
+}
+
+func theResponseBodyContains(text string) error {
+	if got := response.Body.String(); !strings.Contains(got, text) {
+		return fmt.Errorf("expected response body to contain `%s` but got `%s`", text, got)
+	}
+	return nil
+}
+
+func serveRecovered(h http.HandlerFunc) {
+	response = httptest.NewRecorder()
+	defer func() {
+		recovered = recover()
+	}()
+	Recover(h, serverOptions...).ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func panickingHandler(v interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		panic(v)
+	}
+}
+
+func aHandlerPanicsWith(value string) error {
+	serveRecovered(panickingHandler(value))
+	return nil
+}
+
+func aHandlerPanicsWithTheError() error {
+	serveRecovered(panickingHandler(expectedError))
+	return nil
+}
+
+// hijackableRecorder is a ResponseRecorder whose connection can be taken over
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, other := net.Pipe()
+	_ = other.Close()
+	return conn, bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)), nil
+}
+
+func aHandlerPanicsAfterItHijackedTheConnection() error {
+	response = httptest.NewRecorder()
+	defer func() {
+		recovered = recover()
+	}()
+	Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			panic("the response cannot be hijacked")
+		}
+		conn, _, err := hijacker.Hijack()
+		if err != nil {
+			panic(err)
+		}
+		_ = conn.Close()
+		panic("too late")
+	}), serverOptions...).ServeHTTP(hijackableRecorder{response}, httptest.NewRequest(http.MethodGet, "/", nil))
+	return nil
+}
+
+func aHandlerPanicsAfterItWroteTheStatus(code int) error {
+	serveRecovered(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+		panic("too late")
+	})
+	return nil
+}
+
+func aHandlerAbortsTheResponse() error {
+	serveRecovered(panickingHandler(http.ErrAbortHandler))
+	return nil
+}
+
+func thePanicWasNotRecovered() error {
+	if recovered != http.ErrAbortHandler {
+		return fmt.Errorf("expected the panic to be passed on but got `%v`", recovered)
+	}
+	return nil
+}
+
+func theLoggedErrorHasTheStackOfThePanic() error {
+	if len(serverLogged) == 0 {
+		return fmt.Errorf("expected the server to log an error")
+	}
+	info, ok := Debug(serverLogged[0])
+	if !ok || len(info.Stack) == 0 {
+		return fmt.Errorf("expected the logged error to have a stack")
+	}
+	if !strings.Contains(info.Stack[0], "panickingHandler") {
+		return fmt.Errorf("expected the stack to start at the panic but got `%v`", info.Stack)
+	}
+	return nil
+}
+
+func theLoggedErrorMessageIs(message string) error {
+	if len(serverLogged) == 0 {
+		return fmt.Errorf("expected the server to log an error")
+	}
+	if got := serverLogged[0].Error(); got != message {
+		return fmt.Errorf("expected the logged error message to be `%s` but got `%s`", message, got)
+	}
+	return nil
+}
+
+func theLoggedErrorHasThePublicMessage(message string) error {
+	if len(serverLogged) == 0 {
+		return fmt.Errorf("expected the server to log an error")
+	}
+	if got := PublicMessage(serverLogged[0]); got != message {
+		return fmt.Errorf("expected the logged public message to be `%s` but got `%s`", message, got)
+	}
+	return nil
+}
+
+func theLoggedErrorHasTheCause(message string) error {
+	if len(serverLogged) == 0 {
+		return fmt.Errorf("expected the server to log an error")
+	}
+	info, _ := Debug(serverLogged[0])
+	for _, cause := range info.Causes {
+		if cause == message {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected the logged error to have the cause `%s` but got `%v`", message, info.Causes)
+}
+
+func theLoggedErrorIsA(name string) error {
+	if len(serverLogged) == 0 {
+		return fmt.Errorf("expected the server to log an error")
+	}
+	if !Is(serverLogged[0], convertErrNameToError(name)) {
+		return fmt.Errorf("expected the logged error `%v` to be a `%s`", serverLogged[0], name)
+	}
+	return nil
+}
+
+func theErrorHasTheFieldViolation(field, description string) error {
+	expectedError = WithFieldViolation(expectedError, field, description)
+	return nil
+}
+
+func theErrorIsJoinedWith(name, message string) error {
+	expectedError = Join(expectedError, convertErrNameToError(name).Msg(message))
+	return nil
+}
+
+func theErrorIsWrittenAsJSONAPIErrors() error {
+	response = httptest.NewRecorder()
+	WriteJSONAPIErrors(response, httptest.NewRequest(http.MethodGet, "/", nil), expectedError)
+	return nil
+}
+
+func theJSONAPIErrorsAreParsed() error {
+	d, err := ParseJSONAPIErrors(response.Body)
+	if err != nil {
+		return err
+	}
+	expectedError = d.Err()
+	return nil
+}
+
+func jsonapiErrors() ([]map[string]interface{}, error) {
+	var d struct {
+		Errors []map[string]interface{} `json:"errors"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &d); err != nil {
+		return nil, err
+	}
+	return d.Errors, nil
+}
+
+func thereAreJSONAPIErrors(count int) error {
+	errs, err := jsonapiErrors()
+	if err != nil {
+		return err
+	}
+	if len(errs) != count {
+		return fmt.Errorf("expected `%d` JSON:API errors but got `%d`", count, len(errs))
+	}
+	return nil
+}
+
+func jsonapiErrorMember(index int, path string) (interface{}, error) {
+	errs, err := jsonapiErrors()
+	if err != nil {
+		return nil, err
+	}
+	if index < 1 || index > len(errs) {
+		return nil, fmt.Errorf("expected JSON:API error `%d` but there are `%d`", index, len(errs))
+	}
+	var value interface{} = errs[index-1]
+	for _, name := range strings.Split(path, ".") {
+		object, _ := value.(map[string]interface{})
+		value = object[name]
+	}
+	return value, nil
+}
+
+func theJSONAPIErrorHasOf(index int, path, value string) error {
+	got, err := jsonapiErrorMember(index, path)
+	if err != nil {
+		return err
+	}
+	if got == nil || fmt.Sprint(got) != value {
+		return fmt.Errorf("expected JSON:API error `%d` member `%s` to be `%s` but got `%v`", index, path, value, got)
+	}
+	return nil
+}
+
+func theJSONAPIErrorHasNo(index int, path string) error {
+	got, err := jsonapiErrorMember(index, path)
+	if err != nil {
+		return err
+	}
+	if got != nil {
+		return fmt.Errorf("expected JSON:API error `%d` to have no member `%s` but got `%v`", index, path, got)
+	}
+	return nil
+}
+
+func theErrorHasFieldViolations(count int) error {
+	if got := FieldViolations(expectedError); len(got) != count {
+		return fmt.Errorf("expected `%d` field violations but got `%v`", count, got)
+	}
+	return nil
+}
+
+func theFieldViolationIs(index int, field, description string) error {
+	violations := FieldViolations(expectedError)
+	if index < 1 || index > len(violations) {
+		return fmt.Errorf("expected field violation `%d` but got `%v`", index, violations)
+	}
+	if got := violations[index-1]; got.Field != field || got.Description != description {
+		return fmt.Errorf("expected field violation `%d` to be `%s: %s` but got `%s: %s`", index, field, description, got.Field, got.Description)
+	}
+	return nil
+}
+
+func formatForGraphQL(opts ...Option) error {
+	body, err := json.Marshal(map[string]interface{}{"data": nil, "errors": NewGraphQLErrors(expectedError, opts...)})
+	if err != nil {
+		return err
+	}
+	graphqlResponse = body
+	return nil
+}
+
+func theErrorIsFormattedForGraphQL() error {
+	return formatForGraphQL()
+}
+
+func aGraphQLResponseWithTheErrors(doc *godog.DocString) error {
+	graphqlResponse = []byte(doc.Content)
+	return nil
+}
+
+func theGraphQLErrorsAreParsed() error {
+	errs, err := ParseGraphQLErrors(strings.NewReader(string(graphqlResponse)))
+	if err != nil {
+		return err
+	}
+	expectedError = errs.Err()
+	return nil
+}
+
+func theGraphQLErrorHasOf(path, value string) error {
+	var response struct {
+		Errors []interface{} `json:"errors"`
+	}
+	if err := json.Unmarshal(graphqlResponse, &response); err != nil {
+		return err
+	}
+	if len(response.Errors) == 0 {
+		return fmt.Errorf("expected a GraphQL error")
+	}
+	got := response.Errors[0]
+	for _, name := range strings.Split(path, ".") {
+		switch v := got.(type) {
+		case map[string]interface{}:
+			got = v[name]
+		case []interface{}:
+			i, err := strconv.Atoi(name)
+			if err != nil || i >= len(v) {
+				got = nil
+				continue
+			}
+			got = v[i]
+		}
+	}
+	if got == nil || fmt.Sprint(got) != value {
+		return fmt.Errorf("expected GraphQL error member `%s` to be `%s` but got `%v`", path, value, got)
+	}
+	return nil
+}
+
+func theServerRendersErrorsAsPlainText() error {
+	serverOptions = append(serverOptions, WithRenderer(func(w http.ResponseWriter, r *http.Request, err error) {
+		http.Error(w, TypeCode(err), HTTPCode(err))
+	}))
+	return nil
+}
+
+func theResponseBodyIs(body string) error {
+	if got := strings.TrimSpace(response.Body.String()); got != body {
+		return fmt.Errorf("expected response body to be `%s` but got `%s`", body, got)
+	}
+	return nil
+}
+
+func theServerRespondsWithTheStatus(code int) error {
+	response = httptest.NewRecorder()
+	response.WriteHeader(code)
+	return nil
+}
+
+func theServerRespondsWithTheStatusAndTheTypeCode(code int, typeCode string) error {
+	response = httptest.NewRecorder()
+	response.Header().Set(TypeCodeHeader, typeCode)
+	response.WriteHeader(code)
+	return nil
+}
+
+func theServerRespondsWithTheStatusAndTheHeaderOf(code int, name, value string) error {
+	response = httptest.NewRecorder()
+	response.Header().Set(name, value)
+	response.WriteHeader(code)
+	return nil
+}
+
+func theServerRespondsWithTheStatusAndTheBody(code int, body string) error {
+	response = httptest.NewRecorder()
+	http.Error(response, body, code)
+	return nil
+}
+
+func theServerRespondsWithTheStatusAndTheProblem(code int, doc *godog.DocString) error {
+	response = httptest.NewRecorder()
+	response.Header().Set("Content-Type", ProblemContentType)
+	response.WriteHeader(code)
+	_, err := response.WriteString(doc.Content)
+	return err
+}
+
+func theResponseIsReceived() error {
+	received = response.Result()
+	expectedError = ReceiveHTTPError(received, receiveOptions()...)
+	return nil
+}
+
+func sendThroughTheTransport(ctx context.Context, url string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		expectedError = err
+		return
+	}
+	received, expectedError = Do(&http.Client{Transport: Transport{}}, req, receiveOptions()...)
+}
+
+func theRequestIsSentThroughTheTransport() error {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, values := range response.Header() {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(response.Code)
+		_, _ = w.Write(response.Body.Bytes())
+	}))
+	defer server.Close()
+	sendThroughTheTransport(context.Background(), server.URL)
+	return nil
+}
+
+func theRequestIsSentThroughTheTransportToAClosedServer() error {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	sendThroughTheTransport(context.Background(), server.URL)
+	return nil
+}
+
+func theRequestThroughTheTransportTimesOut() error {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	sendThroughTheTransport(ctx, server.URL)
+	return nil
+}
+
+func receivedBody() (string, error) {
+	defer received.Body.Close()
+	body, err := io.ReadAll(received.Body)
+	return string(body), err
+}
+
+func theReceivedStatusIs(code int) error {
+	if received == nil {
+		return fmt.Errorf("expected a response with the status `%d` but got none", code)
+	}
+	if received.StatusCode != code {
+		return fmt.Errorf("expected received status to be `%d` but got `%d`", code, received.StatusCode)
+	}
+	return nil
+}
+
+func theReceivedBodyIs(body string) error {
+	got, err := receivedBody()
+	if err != nil {
+		return err
+	}
+	if got = strings.TrimSpace(got); got != body {
+		return fmt.Errorf("expected received body to be `%s` but got `%s`", body, got)
+	}
+	return nil
+}
+
+func theReceivedBodyContains(text string) error {
+	got, err := receivedBody()
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(got, text) {
+		return fmt.Errorf("expected received body to contain `%s` but got `%s`", text, got)
+	}
+	return nil
+}
+
+func theProblemIsParsed() error {
+	p, err := ParseProblem(response.Body)
+	if err != nil {
+		return err
+	}
+	expectedError = p.Err()
+	return nil
+}
+
+func theResponseStatusIs(code int) error {
+	if response.Code != code {
+		return fmt.Errorf("expected response status to be `%d` but got `%d`", code, response.Code)
+	}
+	return nil
+}
+
+func theResponseHeaderIs(name, value string) error {
+	if got := response.Header().Get(name); got != value {
+		return fmt.Errorf("expected response header `%s` to be `%s` but got `%s`", name, value, got)
+	}
+	return nil
+}
+
+func theResponseHasNoHeader(name string) error {
+	if got := response.Header().Get(name); got != "" {
+		return fmt.Errorf("expected no response header `%s` but got `%s`", name, got)
+	}
+	return nil
+}
+
+func responseMembers() (map[string]interface{}, error) {
+	var members map[string]interface{}
+	if err := json.Unmarshal(response.Body.Bytes(), &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func theProblemMemberIs(name, value string) error {
+	members, err := responseMembers()
+	if err != nil {
+		return err
+	}
+	got, ok := members[name]
+	if !ok {
+		return fmt.Errorf("expected problem member `%s` but there was none", name)
+	}
+	if fmt.Sprint(got) != value {
+		return fmt.Errorf("expected problem member `%s` to be `%s` but got `%v`", name, value, got)
+	}
+	return nil
+}
+
+func theProblemHasNoMember(name string) error {
+	members, err := responseMembers()
+	if err != nil {
+		return err
+	}
+	if got, ok := members[name]; ok {
+		return fmt.Errorf("expected no problem member `%s` but got `%v`", name, got)
+	}
+	return nil
+}
+
+func contextClassificationIsDisabled() error {
+	ClassifyContextErrors(false)
+	return nil
+}
+
+func theErrorIsAnnotatedWith(message string) error {
+	expectedError = fmt.Errorf("%s: %w", message, expectedError)
+	return nil
+}
+
+func theErrorHasNoDebugInfo() error {
+	if info, ok := Debug(expectedError); ok {
+		return fmt.Errorf("expected no debug info but got `%v`", info)
+	}
+	return nil
+}
+
+func theDebugCausesInclude(message string) error {
+	info, _ := Debug(expectedError)
+	for _, cause := range info.Causes {
+		if cause == message {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected debug causes to include `%s` but got `%v`", message, info.Causes)
+}
+
+func theDebugCausesAre(causes string) error {
+	info, _ := Debug(expectedError)
+	if got := strings.Join(info.Causes, ", "); got != causes {
+		return fmt.Errorf("expected debug causes to be `%s` but got `%s`", causes, got)
+	}
+	return nil
+}
+
+func theDebugCausesIncludeTheMultiLineMessage(doc *godog.DocString) error {
+	return theDebugCausesInclude(doc.Content)
+}
+
+func theDebugStackIncludes(function string) error {
+	info, _ := Debug(expectedError)
+	for _, frame := range info.Stack {
+		if strings.Contains(frame, function) {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected debug stack to include `%s` but got `%v`", function, info.Stack)
+}
+
+func theServerLogsErrors() error {
+	serverOptions = append(serverOptions, WithLogHook(func(ctx context.Context, err error) {
+		serverLogged = append(serverLogged, err)
+	}))
+	return nil
+}
+
+func theClientUsesTheErrorInterceptors() error {
+	clientInterceptors = true
+	return nil
+}
+
+func theServerMasksInternalMessages() error {
+	serverOptions = append(serverOptions, WithMasking())
+	return nil
+}
+
+func theErrorIsReturnedByAUnaryGRPCHandler() error {
+	start := time.Now()
+	expectedError = callTestServer(expectedError, false, serverOptions, clientInterceptors)
+	callDuration = time.Since(start)
+	return nil
+}
+
+func theErrorIsReturnedByAStreamingGRPCHandler() error {
+	expectedError = callTestServer(expectedError, true, serverOptions, clientInterceptors)
+	return nil
+}
+
+func theServerLogged(typeCode string) error {
+	for _, err := range serverLogged {
+		if TypeCode(err) == typeCode {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected the server to log a `%s` error but got `%v`", typeCode, serverLogged)
+}
+
+func theErrorIsNotA(errName string) error {
+	if Is(expectedError, convertErrNameToError(errName)) {
+		return fmt.Errorf("expected error not to be a `%s`", errName)
+	}
+	return nil
+}
+
+func theErrorHasTheID(id string) error {
+	expectedError = WithID(expectedError, id)
+	return nil
+}
+
+func theErrorHasThePublicMessage(message string) error {
+	expectedError = WithPublicMessage(expectedError, message)
+	return nil
+}
+
+func theErrorHasTheFieldWithTheValue(key, value string) error {
+	expectedError = WithField(expectedError, key, value)
+	return nil
+}
+
+func theErrorHasTheRetryDelay(delay string) error {
+	d, err := time.ParseDuration(delay)
+	if err != nil {
+		return err
+	}
+	expectedError = WithRetryDelay(expectedError, d)
+	return nil
+}
+
+func theErrorHasTheRateLimitWithRemainingResettingIn(limit, remaining int, reset string) error {
+	d, err := time.ParseDuration(reset)
+	if err != nil {
+		return err
+	}
+	expectedError = WithRateLimit(expectedError, limit, remaining, d)
+	return nil
+}
+
+func theErrorIsMarkedNotRetryable() error {
+	expectedError = WithRetryable(expectedError, false)
+	return nil
+}
+
+func aVersion1ErrorTypeWasReceived(errName string) error {
+	errType := convertErrNameToError(errName)
+	s, err := status.New(errType.GRPCCode(), "v1 error").WithDetails(&ErrorType{
+		TypeCode: errType.TypeCode(),
+		HTTPCode: int64(errType.HTTPCode()),
+		GRPCCode: int64(errType.GRPCCode()),
+	})
+	if err != nil {
+		return err
+	}
+	expectedError = ReceiveGRPCError(s.Err())
+	return nil
+}
+
+func theIDIs(id string) error {
+	if got := ID(expectedError); got != id {
+		return fmt.Errorf("expected ID to be `%s` but got `%s`", id, got)
+	}
+	return nil
+}
+
+func thePublicMessageIs(message string) error {
+	if got := PublicMessage(expectedError); got != message {
+		return fmt.Errorf("expected public message to be `%s` but got `%s`", message, got)
+	}
+	return nil
+}
+
+func theFieldIs(key, value string) error {
+	if got := Fields(expectedError)[key]; got != value {
+		return fmt.Errorf("expected field `%s` to be `%s` but got `%s`", key, value, got)
+	}
+	return nil
+}
+
+func theRetryDelayIs(delay string) error {
+	got, ok := RetryDelay(expectedError)
+	if !ok || got.String() != delay {
+		return fmt.Errorf("expected retry delay to be `%s` but got `%s`", delay, got)
+	}
+	return nil
+}
+
+func theRateLimitIsWithRemainingResettingIn(limit, remaining int, reset string) error {
+	d, err := time.ParseDuration(reset)
+	if err != nil {
+		return err
+	}
+	want := RateLimit{Limit: limit, Remaining: remaining, Reset: d}
+	if got, ok := Quota(expectedError); !ok || got != want {
+		return fmt.Errorf("expected rate limit to be `%+v` but got `%+v`", want, got)
+	}
+	return nil
+}
+
+func theErrorHasNoRateLimit() error {
+	if got, ok := Quota(expectedError); ok {
+		return fmt.Errorf("expected no rate limit but got `%+v`", got)
+	}
+	return nil
+}
+
+func theErrorIsRetryable() error {
+	if !Retryable(expectedError) {
+		return fmt.Errorf("expected error to be retryable")
+	}
+	return nil
+}
+
+func theErrorIsNotRetryable() error {
+	if Retryable(expectedError) {
+		return fmt.Errorf("expected error not to be retryable")
+	}
+	return nil
+}
+
+func theErrorHasATimestamp() error {
+	if got := Timestamp(expectedError); time.Since(got) > time.Minute {
+		return fmt.Errorf("expected a recent timestamp but got `%s`", got)
+	}
+	return nil
+}
+
+func theErrorHasNoTimestamp() error {
+	if got := Timestamp(expectedError); !got.IsZero() {
+		return fmt.Errorf("expected no timestamp but got `%s`", got)
+	}
+	return nil
+}
+
+func theResourceIsWrappedWithTheError(resourceType, name, errName string) error {
+	expectedError = convertErrNameToError(errName).Wrap(resourceTestError{resourceType: resourceType, name: name}, "resource error")
+	return nil
+}
+
+func theErrorIsTheResource(resourceType, name string) error {
+	var target resourceTestError
+	if !As(expectedError, &target) {
+		return fmt.Errorf("expected error to be a resource error")
+	}
+	if target.resourceType != resourceType || target.name != name {
+		return fmt.Errorf("expected resource `%s %s` but got `%s`", resourceType, name, target)
+	}
+	return nil
+}
+
+func convertStdErrNameToError(errName string) error {
+	switch errName {
+	case "io.EOF":
+		return io.EOF
+	case "context.Canceled":
+		return context.Canceled
+	case "context.DeadlineExceeded":
+		return context.DeadlineExceeded
+	default:
+		return stderrors.New(errName)
+	}
+}
+
+func theErrorIsTheStandardError(errName string) error {
+	expectedError = convertStdErrNameToError(errName)
+	return nil
+}
+
+func theErrorIsReceivedFromGRPC() error {
+	expectedError = ReceiveGRPCError(expectedError, receiveOptions()...)
+	return nil
+}
+
+func theErrorWraps(errName string) error {
+	if !Is(expectedError, convertStdErrNameToError(errName)) {
+		return fmt.Errorf("expected error to wrap `%s`", errName)
+	}
+	return nil
+}
+
+func InitializeTestSuite(ctx *godog.TestSuiteContext) {
+	ctx.BeforeSuite(func() {
+		expectedError = ErrUnknown
+	})
+}
+
+func InitializeScenario(ctx *godog.ScenarioContext) {
+	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+		expectedError = stderrors.New("test error")
+		serverOptions = nil
+		serverLogged = nil
+		clientInterceptors = false
+		clientOptions = nil
+		convertedStatus = nil
+		clientRetries = nil
+		response = nil
+		received = nil
+		renderOptions = nil
+		recovered = nil
+		graphqlResponse = nil
+		callTimeout = 0
+		callDuration = 0
+		serverCalls.Store(0)
+		serverFailures = 0
+		clientTrust = nil
+		mismatches = nil
+		return ctx, nil
+	})
+	ctx.After(func(ctx context.Context, sc *godog.Scenario, err error) (context.Context, error) {
+		EnableDebugInfo(false)
+		ClassifyContextErrors(true)
+		SetMatchMode(MatchEquivalent)
+		SetTrustPolicy(TrustPolicy{})
+		SetStatusBudget(DefaultStatusBudget)
 		return ctx, nil
 	})
 
@@ -392,21 +1875,171 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^the error does not implement (:?Type|HTTP|GRPC)Coder{}$`, theErrorDoesNotImplementCoderInterfaces)
 	ctx.Step(`^the error is "([^"]*)"$`, theErrorIs)
 	ctx.Step(`^the error is nil$`, theErrorIsNil)
+	ctx.Step(`^the error is the standard error "([^"]*)"$`, theErrorIsTheStandardError)
 	ctx.Step(`^the error sent by a GRPC server was "([^"]*)"$`, theErrorSentByAGRPCServerWas)
 	ctx.Step(`^an error with the message "([^"]*)"$`, anErrorWithTheMessage)
 	ctx.Step(`^an error with Type code "([^"]*)"$`, anErrorWithTypeCode)
 	ctx.Step(`^an error with HTTP status "([^"]*)"$`, anErrorWithHTTPStatus)
 	ctx.Step(`^an error with GRPC code "([^"]*)"$`, anErrorWithGRPCCode)
+	ctx.Step(`^a "([^"]*)" status error with the message "([^"]*)"$`, aStatusErrorWithTheMessage)
+	ctx.Step(`^the help link "([^"]*)" is attached$`, theHelpLinkIsAttached)
+	ctx.Step(`^the help link "([^"]*)" is registered for "([^"]*)"$`, theHelpLinkIsRegisteredFor)
+	ctx.Step(`^the localized message "([^"]*)" is attached for "([^"]*)"$`, theLocalizedMessageIsAttachedFor)
+	ctx.Step(`^debug info is enabled$`, debugInfoIsEnabled)
+	ctx.Step(`^context classification is disabled$`, contextClassificationIsDisabled)
+	ctx.Step(`^the status budget is (\d+) bytes$`, theStatusBudgetIsBytes)
+	ctx.Step(`^strict matching is enabled$`, strictMatchingIsEnabled)
+	ctx.Step(`^the client matches strictly$`, theClientMatchesStrictly)
+	ctx.Step(`^the client retries up to (\d+) times$`, theClientRetriesUpToTimes)
+	ctx.Step(`^the call times out after "([^"]*)"$`, theCallTimesOutAfter)
+	ctx.Step(`^the handler succeeds after (\d+) failures?$`, theHandlerSucceedsAfterFailures)
+	ctx.Step(`^a "([^"]*)" status was sent with an ErrorType claiming HTTP (\d+) and GRPC "([^"]*)"$`, aStatusWasSentWithAnErrorTypeClaiming)
+	ctx.Step(`^a "([^"]*)" status was sent with a RetryInfo of "([^"]*)"$`, aStatusWasSentWithARetryInfoOf)
+	ctx.Step(`^a "([^"]*)" status was sent without details$`, aStatusWasSentWithoutDetails)
+	ctx.Step(`^the client (rejects|clamps) HTTP codes that are not errors$`, theClientHandlesHTTPCodes)
+	ctx.Step(`^the client verifies GRPC codes$`, theClientVerifiesGRPCCodes)
+	ctx.Step(`^the client does not accept details from "([^"]*)"$`, theClientDoesNotAcceptDetailsFrom)
+	ctx.Step(`^details are not accepted from any server$`, detailsAreNotAcceptedFromAnyServer)
+	ctx.Step(`^the error has the ID "([^"]*)"$`, theErrorHasTheID)
+	ctx.Step(`^the error has the public message "([^"]*)"$`, theErrorHasThePublicMessage)
+	ctx.Step(`^the error has the field "([^"]*)" with the value "([^"]*)"$`, theErrorHasTheFieldWithTheValue)
+	ctx.Step(`^the error has a public message of (\d+) bytes$`, theErrorHasThePublicMessageOfBytes)
+	ctx.Step(`^the error has the field "([^"]*)" with a value of (\d+) bytes$`, theErrorHasTheFieldWithAValueOfBytes)
+	ctx.Step(`^the error has the retry delay "([^"]*)"$`, theErrorHasTheRetryDelay)
+	ctx.Step(`^a version 1 ErrorType for "([^"]*)" was received$`, aVersion1ErrorTypeWasReceived)
+	ctx.Step(`^the error is marked as not retryable$`, theErrorIsMarkedNotRetryable)
+	ctx.Step(`^the error has the rate limit (\d+) with (\d+) remaining resetting in "([^"]*)"$`, theErrorHasTheRateLimitWithRemainingResettingIn)
+	ctx.Step(`^the "([^"]*)" resource "([^"]*)" is wrapped with the error "([^"]*)"$`, theResourceIsWrappedWithTheError)
+	ctx.Step(`^the server logs errors$`, theServerLogsErrors)
+	ctx.Step(`^the server masks internal messages$`, theServerMasksInternalMessages)
+	ctx.Step(`^the server renders errors as plain text$`, theServerRendersErrorsAsPlainText)
+	ctx.Step(`^the client uses the error interceptors$`, theClientUsesTheErrorInterceptors)
 
 	// When
 	ctx.Step(`^wrapped with the error "([^"]*)" and message "([^"]*)"$`, wrappedWithTheError)
 	ctx.Step(`^wrapped with the message "([^"]*)"$`, wrappedWithTheMessage)
+	ctx.Step(`^wrapped with the message:$`, wrappedWithTheMultiLineMessage)
+	ctx.Step(`^annotated with the message "([^"]*)"$`, theErrorIsAnnotatedWith)
+	ctx.Step(`^wrapped with a message of (\d+) bytes$`, wrappedWithAMessageOfBytes)
+	ctx.Step(`^wrapped with a non-ASCII message of (\d+) bytes$`, wrappedWithANonASCIIMessageOfBytes)
+	ctx.Step(`^the error is converted to a status$`, theErrorIsConvertedToAStatus)
+	ctx.Step(`^the error is written as a problem$`, theErrorIsWrittenAsAProblem)
+	ctx.Step(`^the error is written as a problem to a client accepting "([^"]*)"$`, theErrorIsWrittenAsAProblemToAClientAccepting)
+	ctx.Step(`^the problem is parsed$`, theProblemIsParsed)
+	ctx.Step(`^the server responds with the status (\d+)$`, theServerRespondsWithTheStatus)
+	ctx.Step(`^the server responds with the status (\d+) and the type code "([^"]*)"$`, theServerRespondsWithTheStatusAndTheTypeCode)
+	ctx.Step(`^the server responds with the status (\d+) and the header "([^"]*)" of "([^"]*)"$`, theServerRespondsWithTheStatusAndTheHeaderOf)
+	ctx.Step(`^the server responds with the status (\d+) and the body "([^"]*)"$`, theServerRespondsWithTheStatusAndTheBody)
+	ctx.Step(`^the server responds with the status (\d+) and the problem:$`, theServerRespondsWithTheStatusAndTheProblem)
+	ctx.Step(`^the response is received$`, theResponseIsReceived)
+	ctx.Step(`^the request is sent through the transport$`, theRequestIsSentThroughTheTransport)
+	ctx.Step(`^the request is sent through the transport to a closed server$`, theRequestIsSentThroughTheTransportToAClosedServer)
+	ctx.Step(`^the request through the transport times out$`, theRequestThroughTheTransportTimesOut)
+	ctx.Step(`^the error is returned by an HTTP handler$`, theErrorIsReturnedByAnHTTPHandler)
+	ctx.Step(`^the error is returned by an HTTP handler after it wrote the status (\d+)$`, theErrorIsReturnedByAnHTTPHandlerAfterItWroteTheStatus)
+	ctx.Step(`^the error is rendered for a client accepting "([^"]*)"$`, theErrorIsRenderedForAClientAccepting)
+	ctx.Step(`^the renderer writes "([^"]*)" as "(.*)"$`, theRendererWritesAs)
+	ctx.Step(`^the renderer uses the HTML template "([^"]*)"$`, theRendererUsesTheHTMLTemplate)
+	ctx.Step(`^the error is returned by a HandlerFunc$`, theErrorIsReturnedByAHandlerFunc)
+	ctx.Step(`^the error is converted to a status with the default type "([^"]*)"$`, theErrorIsConvertedToAStatusWithTheDefaultType)
+	ctx.Step(`^the error is converted to a status with masking$`, theErrorIsConvertedToAStatusWithMasking)
+	ctx.Step(`^the error is converted to a status with an ErrorInfo encoder$`, theErrorIsConvertedToAStatusWithAnErrorInfoEncoder)
+	ctx.Step(`^an OK status is converted$`, anOKStatusIsConverted)
+	ctx.Step(`^the status is converted to an error$`, theStatusIsConvertedToAnError)
+	ctx.Step(`^the error is sent over GRPC with a budget of (\d+) bytes$`, theErrorIsSentOverGRPCWithABudgetOfBytes)
 	ctx.Step(`^the error is sent over GRPC$`, theErrorIsSentOverGRPC)
+	ctx.Step(`^the error is sent over GRPC from "([^"]*)" with the default type "([^"]*)"$`, theErrorIsSentOverGRPCFromWithTheDefaultType)
+	ctx.Step(`^an upstream service "([^"]*)" sent the error with an ErrorInfo and a QuotaFailure$`, anUpstreamServiceSentTheErrorWithAnErrorInfoAndAQuotaFailure)
+	ctx.Step(`^the error is relayed over GRPC by "([^"]*)"$`, theErrorIsRelayedOverGRPCBy)
+	ctx.Step(`^the status has a QuotaFailure$`, theStatusHasAQuotaFailure)
+	ctx.Step(`^the origin is "([^"]*)"$`, theOriginIs)
+	ctx.Step(`^the error is received from GRPC$`, theErrorIsReceivedFromGRPC)
+	ctx.Step(`^the error is sent over GRPC and received with strict matching$`, theErrorIsSentOverGRPCAndReceivedWithStrictMatching)
+	ctx.Step(`^the error is returned by a unary GRPC handler$`, theErrorIsReturnedByAUnaryGRPCHandler)
+	ctx.Step(`^the error is returned by a streaming GRPC handler$`, theErrorIsReturnedByAStreamingGRPCHandler)
+	ctx.Step(`^the error is sent over GRPC with the cause chain$`, theErrorIsSentOverGRPCWithTheCauseChain)
+	ctx.Step(`^the error is sent over GRPC with a timestamp$`, theErrorIsSentOverGRPCWithATimestamp)
+	ctx.Step(`^the error is sent over GRPC to a client accepting "([^"]*)"$`, theErrorIsSentOverGRPCToAClientAccepting)
 
 	// Then
 	ctx.Step(`^the Type code is "([^"]*)"$`, theTypeCodeIs)
 	ctx.Step(`^the HTTP status is "([^"]*)"$`, theHTTPStatusIs)
+	ctx.Step(`^the HTTP code is (\d+)$`, theHTTPCodeIs)
 	ctx.Step(`^the GRPC code is "([^"]*)"$`, theGRPCCodeIs)
 	ctx.Step(`^the error message is "([^"]*)"$`, theErrorMessageIs)
 	ctx.Step(`^the error is a "([^"]*)"$`, theErrorIsA)
+	ctx.Step(`^a "([^"]*)" mismatch was reported$`, aMismatchWasReported)
+	ctx.Step(`^a "([^"]*)" mismatch was reported for "([^"]*)"$`, aMismatchWasReportedFor)
+	ctx.Step(`^no mismatch was reported$`, noMismatchWasReported)
+	ctx.Step(`^the call succeeded$`, theCallSucceeded)
+	ctx.Step(`^the response status is (\d+)$`, theResponseStatusIs)
+	ctx.Step(`^the response header "([^"]*)" is "([^"]*)"$`, theResponseHeaderIs)
+	ctx.Step(`^the response has no header "([^"]*)"$`, theResponseHasNoHeader)
+	ctx.Step(`^the response body is "(.*)"$`, theResponseBodyIs)
+	ctx.Step(`^the response body contains "(.*)"$`, theResponseBodyContains)
+	ctx.Step(`^the received status is (\d+)$`, theReceivedStatusIs)
+	ctx.Step(`^the received body is "([^"]*)"$`, theReceivedBodyIs)
+	ctx.Step(`^the received body contains "(.*)"$`, theReceivedBodyContains)
+	ctx.Step(`^the problem member "([^"]*)" is "([^"]*)"$`, theProblemMemberIs)
+	ctx.Step(`^the problem has no member "([^"]*)"$`, theProblemHasNoMember)
+	ctx.Step(`^the handler was called (\d+) times?$`, theHandlerWasCalledTimes)
+	ctx.Step(`^the call took at least "([^"]*)"$`, theCallTookAtLeast)
+	ctx.Step(`^the call took less than "([^"]*)"$`, theCallTookLessThan)
+	ctx.Step(`^the status code is "([^"]*)"$`, theStatusCodeIs)
+	ctx.Step(`^the status message is "([^"]*)"$`, theStatusMessageIs)
+	ctx.Step(`^the status has an ErrorInfo with the reason "([^"]*)"$`, theStatusHasAnErrorInfoWithTheReason)
+	ctx.Step(`^the status is not an error$`, theStatusIsNotAnError)
+	ctx.Step(`^the error is truncated$`, theErrorIsTruncated)
+	ctx.Step(`^the error is not truncated$`, theErrorIsNotTruncated)
+	ctx.Step(`^the error message is shorter than (\d+) bytes$`, theErrorMessageIsShorterThanBytes)
+	ctx.Step(`^the public message is shorter than (\d+) bytes$`, thePublicMessageIsShorterThanBytes)
+	ctx.Step(`^the public message ends with "([^"]*)"$`, thePublicMessageEndsWith)
+	ctx.Step(`^the error message ends with "([^"]*)"$`, theErrorMessageEndsWith)
+	ctx.Step(`^the error is not a "([^"]*)"$`, theErrorIsNotA)
+	ctx.Step(`^the error wraps "([^"]*)"$`, theErrorWraps)
+	ctx.Step(`^the help links include "([^"]*)"$`, theHelpLinksInclude)
+	ctx.Step(`^the error has no help links$`, theErrorHasNoHelpLinks)
+	ctx.Step(`^the localized message is "([^"]*)"$`, theLocalizedMessageIs)
+	ctx.Step(`^the error has no debug info$`, theErrorHasNoDebugInfo)
+	ctx.Step(`^the debug causes include "([^"]*)"$`, theDebugCausesInclude)
+	ctx.Step(`^the debug causes include:$`, theDebugCausesIncludeTheMultiLineMessage)
+	ctx.Step(`^the debug causes are "([^"]*)"$`, theDebugCausesAre)
+	ctx.Step(`^the debug stack includes "([^"]*)"$`, theDebugStackIncludes)
+	ctx.Step(`^the server logged "([^"]*)"$`, theServerLogged)
+	ctx.Step(`^a handler panics with "([^"]*)"$`, aHandlerPanicsWith)
+	ctx.Step(`^the error is formatted for GraphQL$`, theErrorIsFormattedForGraphQL)
+	ctx.Step(`^a GraphQL response with the errors:$`, aGraphQLResponseWithTheErrors)
+	ctx.Step(`^the GraphQL errors are parsed$`, theGraphQLErrorsAreParsed)
+	ctx.Step(`^the GraphQL error has the "([^"]*)" "([^"]*)"$`, theGraphQLErrorHasOf)
+	ctx.Step(`^the error has the field violation "([^"]*)": "([^"]*)"$`, theErrorHasTheFieldViolation)
+	ctx.Step(`^the error is joined with "([^"]*)" with the message "([^"]*)"$`, theErrorIsJoinedWith)
+	ctx.Step(`^the error is written as JSON:API errors$`, theErrorIsWrittenAsJSONAPIErrors)
+	ctx.Step(`^the JSON:API errors are parsed$`, theJSONAPIErrorsAreParsed)
+	ctx.Step(`^there (?:is|are) (\d+) JSON:API errors?$`, thereAreJSONAPIErrors)
+	ctx.Step(`^JSON:API error (\d+) has the "([^"]*)" "([^"]*)"$`, theJSONAPIErrorHasOf)
+	ctx.Step(`^JSON:API error (\d+) has no "([^"]*)"$`, theJSONAPIErrorHasNo)
+	ctx.Step(`^the error has (\d+) field violations?$`, theErrorHasFieldViolations)
+	ctx.Step(`^field violation (\d+) is "([^"]*)": "([^"]*)"$`, theFieldViolationIs)
+	ctx.Step(`^a handler panics with the error$`, aHandlerPanicsWithTheError)
+	ctx.Step(`^a handler panics after it wrote the status (\d+)$`, aHandlerPanicsAfterItWroteTheStatus)
+	ctx.Step(`^the response was aborted$`, thePanicWasNotRecovered)
+	ctx.Step(`^a handler panics after it hijacked the connection$`, aHandlerPanicsAfterItHijackedTheConnection)
+	ctx.Step(`^a handler aborts the response$`, aHandlerAbortsTheResponse)
+	ctx.Step(`^the panic was not recovered$`, thePanicWasNotRecovered)
+	ctx.Step(`^the logged error has the stack of the panic$`, theLoggedErrorHasTheStackOfThePanic)
+	ctx.Step(`^the logged error is a "([^"]*)"$`, theLoggedErrorIsA)
+	ctx.Step(`^the logged error message is "([^"]*)"$`, theLoggedErrorMessageIs)
+	ctx.Step(`^the logged error has the public message "([^"]*)"$`, theLoggedErrorHasThePublicMessage)
+	ctx.Step(`^the logged error has the cause "([^"]*)"$`, theLoggedErrorHasTheCause)
+	ctx.Step(`^the ID is "([^"]*)"$`, theIDIs)
+	ctx.Step(`^the public message is "([^"]*)"$`, thePublicMessageIs)
+	ctx.Step(`^the field "([^"]*)" is "([^"]*)"$`, theFieldIs)
+	ctx.Step(`^the retry delay is "([^"]*)"$`, theRetryDelayIs)
+	ctx.Step(`^the error is retryable$`, theErrorIsRetryable)
+	ctx.Step(`^the rate limit is (\d+) with (\d+) remaining resetting in "([^"]*)"$`, theRateLimitIsWithRemainingResettingIn)
+	ctx.Step(`^the error has no rate limit$`, theErrorHasNoRateLimit)
+	ctx.Step(`^the error is not retryable$`, theErrorIsNotRetryable)
+	ctx.Step(`^the error has a timestamp$`, theErrorHasATimestamp)
+	ctx.Step(`^the error has no timestamp$`, theErrorHasNoTimestamp)
+	ctx.Step(`^the error is the "([^"]*)" resource "([^"]*)"$`, theErrorIsTheResource)
 }
@@ -0,0 +1,156 @@
+package errors
+
+import (
+	"encoding/base64"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// DefaultStatusBudget is the number of bytes statuses are kept within unless changed
+//
+// Statuses are sent in the grpc-message and grpc-status-details-bin trailers
+// and many proxies and clients limit headers to 8 KiB in total.
+const DefaultStatusBudget = 7 * 1024
+
+var statusBudget atomic.Int64
+
+func init() {
+	statusBudget.Store(DefaultStatusBudget)
+}
+
+// SetStatusBudget sets the number of bytes statuses are kept within when WithStatusBudget() is not used
+//
+// A budget of zero or less turns the limit off.
+func SetStatusBudget(bytes int) {
+	statusBudget.Store(int64(bytes))
+}
+
+// WithStatusBudget overrides SetStatusBudget() for the status being built
+func WithStatusBudget(bytes int) Option {
+	return func(o *options) {
+		o.budget = &bytes
+	}
+}
+
+func (o *options) statusBudget() int {
+	if o.budget != nil {
+		return *o.budget
+	}
+	return int(statusBudget.Load())
+}
+
+// statusDetails are the optional details of a status grouped by what they contain
+type statusDetails struct {
+	chain      []protoadapt.MessageV1
+	help       []protoadapt.MessageV1
+	localized  []protoadapt.MessageV1
+	registered []protoadapt.MessageV1
+	debug      []protoadapt.MessageV1
+	forwarded  []*anypb.Any // details of a received error, sent as they were received
+}
+
+func (d statusDetails) build(code codes.Code, message string, errInfo *ErrorType) *status.Status {
+	details := append([]protoadapt.MessageV1{}, d.chain...)
+	details = append(details, errInfo)
+	details = append(details, d.help...)
+	details = append(details, d.localized...)
+	details = append(details, d.registered...)
+	details = append(details, d.debug...)
+	s, _ := status.New(code, message).WithDetails(details...)
+	if len(d.forwarded) != 0 {
+		p := s.Proto()
+		p.Details = append(p.Details, d.forwarded...)
+		s = status.FromProto(p)
+	}
+	return s
+}
+
+// fitStatus builds a status that is within the budget
+//
+// Details are dropped in order: forwarded details, debug info, registered
+// details, the cause chain, help links, the localized message and then the
+// fields of the ErrorType. When that is not enough the longer of the message
+// and the public message is shortened until the status fits. The ErrorType is
+// always kept and marked as truncated when anything was left out.
+func fitStatus(code codes.Code, message string, errInfo *ErrorType, details statusDetails, budget int) *status.Status {
+	s := details.build(code, message, errInfo)
+	if budget <= 0 || statusSize(s) <= budget {
+		return s
+	}
+
+	errInfo.Truncated = true
+	if len(details.forwarded) != 0 {
+		details.forwarded = nil
+		if s = details.build(code, message, errInfo); statusSize(s) <= budget {
+			return s
+		}
+	}
+	for _, drop := range []*[]protoadapt.MessageV1{&details.debug, &details.registered, &details.chain, &details.help, &details.localized} {
+		if len(*drop) == 0 {
+			continue
+		}
+		*drop = nil
+		if s = details.build(code, message, errInfo); statusSize(s) <= budget {
+			return s
+		}
+	}
+
+	if len(errInfo.Fields) != 0 {
+		errInfo.Fields = nil
+		if s = details.build(code, message, errInfo); statusSize(s) <= budget {
+			return s
+		}
+	}
+
+	// every byte of the message is sent in grpc-message, percent-encoded when it is not printable ASCII, and again,
+	// base64 encoded, in grpc-status-details-bin; the public message is only sent in the latter
+	for over := statusSize(s) - budget; over > 0 && (message != "" || errInfo.PublicMessage != ""); over = statusSize(s) - budget {
+		if len(message) >= len(errInfo.PublicMessage) {
+			message = truncate(message, len(message)-(over*3+12)/13)
+		} else {
+			errInfo.PublicMessage = truncate(errInfo.PublicMessage, len(errInfo.PublicMessage)-(over*3+3)/4)
+		}
+		s = details.build(code, message, errInfo)
+	}
+
+	return s
+}
+
+// statusSize estimates the bytes used by the status when sent as trailers
+func statusSize(s *status.Status) int {
+	return encodedMessageSize(s.Message()) + base64.StdEncoding.EncodedLen(proto.Size(s.Proto()))
+}
+
+// encodedMessageSize returns the length of the message once percent-encoded for the grpc-message trailer
+//
+// Bytes outside printable ASCII, and the percent sign, are sent as three bytes.
+func encodedMessageSize(message string) int {
+	size := 0
+	for i := 0; i < len(message); i++ {
+		if c := message[i]; c >= ' ' && c <= '~' && c != '%' {
+			size++
+		} else {
+			size += 3
+		}
+	}
+	return size
+}
+
+// truncate shortens the message to at most n bytes without splitting runes and marks it with an ellipsis
+func truncate(message string, n int) string {
+	const ellipsis = "..."
+	n -= len(ellipsis)
+	if n <= 0 {
+		return ""
+	}
+	for n > 0 && !utf8.RuneStart(message[n]) {
+		n--
+	}
+	return message[:n] + ellipsis
+}
@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// codedLayers returns an ErrorType for every coded layer of the error, outermost first
+//
+// A layer is any error in the chain which is itself a TypeCoder. Layers that
+// repeat the type code of the layer before them, such as an Error used to
+// override the type of a wrapped error, are skipped.
+func codedLayers(err error) []*ErrorType {
+	var layers []*ErrorType
+	walk(err, func(err error) bool {
+		typeCoder, ok := err.(TypeCoder)
+		if !ok {
+			return true
+		}
+		typeCode := typeCoder.TypeCode()
+		if len(layers) != 0 && layers[len(layers)-1].TypeCode == typeCode {
+			return true
+		}
+		layers = append(layers, &ErrorType{
+			TypeCode: typeCode,
+			HTTPCode: int64(HTTPCode(err)),
+			GRPCCode: int64(GRPCCode(err)),
+			Message:  err.Error(),
+		})
+		return true
+	})
+	return layers
+}
+
+// layersToError rebuilds a chain of received errors from layers ordered innermost first
+func layersToError(layers []*ErrorType, match MatchMode) error {
+	var cause error
+	for _, layer := range layers {
+		grpcCode := codes.Code(layer.GRPCCode)
+		cause = &grpcError{
+			gc:    grpcCode,
+			hc:    int(layer.HTTPCode),
+			m:     layer.Message,
+			t:     layer.TypeCode,
+			s:     status.New(grpcCode, layer.Message),
+			cause: cause,
+			match: match,
+		}
+	}
+	return cause
+}
@@ -0,0 +1,70 @@
+package errors
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryClientInterceptor returns an interceptor that calls ReceiveGRPCError on every error returned by a call
+func UnaryClientInterceptor(options ...Option) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil {
+			return nil
+		}
+		return ReceiveGRPCError(err, withCall(cc, method, options)...)
+	}
+}
+
+// StreamClientInterceptor returns an interceptor that calls ReceiveGRPCError on every error returned by a stream
+//
+// Errors from creating the stream and those returned mid-stream by SendMsg,
+// RecvMsg, CloseSend and Header are all converted. The io.EOF that marks the
+// end of a stream is returned unchanged.
+func StreamClientInterceptor(options ...Option) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		receiveOpts := withCall(cc, method, options)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, ReceiveGRPCError(err, receiveOpts...)
+		}
+		return &clientStream{ClientStream: stream, options: receiveOpts}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	options []Option
+}
+
+func (s *clientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	return md, s.receive(err)
+}
+
+func (s *clientStream) CloseSend() error {
+	return s.receive(s.ClientStream.CloseSend())
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return s.receive(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return s.receive(s.ClientStream.RecvMsg(m))
+}
+
+func (s *clientStream) receive(err error) error {
+	if err == io.EOF {
+		return err
+	}
+	return ReceiveGRPCError(err, s.options...)
+}
+
+// withCall prepends the peer of the call to the options given to the interceptor
+func withCall(cc *grpc.ClientConn, method string, options []Option) []Option {
+	return append([]Option{WithPeer(cc.Target(), method)}, options...)
+}
@@ -0,0 +1,100 @@
+package errors
+
+import (
+	stderrors "errors"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+type detailCodec struct {
+	encode func(err error) (protoadapt.MessageV1, bool)
+	decode func(detail interface{}) (error, bool)
+}
+
+var registeredCodecs = struct {
+	sync.RWMutex
+	codecs []detailCodec
+}{}
+
+// RegisterDetail registers functions to send errors of type E as a detail of type M and to rebuild them when received
+//
+// When a status is built, the first error in the chain that is an E is encoded
+// and added as a detail. When an error is received, every detail that is an M
+// is decoded and can be found with errors.As() and errors.Is(). Both sides
+// must register the same types. Encoders returning nil add no detail.
+func RegisterDetail[E error, M proto.Message](encode func(E) M, decode func(M) E) {
+	registeredCodecs.Lock()
+	defer registeredCodecs.Unlock()
+
+	registeredCodecs.codecs = append(registeredCodecs.codecs, detailCodec{
+		encode: func(err error) (protoadapt.MessageV1, bool) {
+			var e E
+			if !stderrors.As(err, &e) {
+				return nil, false
+			}
+			m := encode(e)
+			if interface{}(m) == nil || !m.ProtoReflect().IsValid() {
+				return nil, false
+			}
+			return protoadapt.MessageV1Of(m), true
+		},
+		decode: func(detail interface{}) (error, bool) {
+			m, ok := detail.(M)
+			if !ok {
+				return nil, false
+			}
+			return decode(m), true
+		},
+	})
+}
+
+// WithDetailEncoder adds the detail returned by encode to the status being built
+//
+// Unlike RegisterDetail() the encoder is only used for the statuses built with
+// this option, and is given the whole error. Encoders returning nil add no detail.
+func WithDetailEncoder(encode func(err error) proto.Message) Option {
+	return func(o *options) {
+		o.encoders = append(o.encoders, encode)
+	}
+}
+
+func (o *options) encodeDetails(err error) []protoadapt.MessageV1 {
+	var details []protoadapt.MessageV1
+	for _, encode := range o.encoders {
+		m := encode(err)
+		if m == nil || !m.ProtoReflect().IsValid() {
+			continue
+		}
+		details = append(details, protoadapt.MessageV1Of(m))
+	}
+	return details
+}
+
+// encodeDetails returns the details for every registered type found in the error
+func encodeDetails(err error) []protoadapt.MessageV1 {
+	registeredCodecs.RLock()
+	defer registeredCodecs.RUnlock()
+
+	var details []protoadapt.MessageV1
+	for _, codec := range registeredCodecs.codecs {
+		if detail, ok := codec.encode(err); ok {
+			details = append(details, detail)
+		}
+	}
+	return details
+}
+
+// decodeDetail rebuilds the registered error type for a received detail
+func decodeDetail(detail interface{}) (error, bool) {
+	registeredCodecs.RLock()
+	defer registeredCodecs.RUnlock()
+
+	for _, codec := range registeredCodecs.codecs {
+		if err, ok := codec.decode(detail); ok {
+			return err, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,40 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"sync/atomic"
+)
+
+var contextErrorsIgnored atomic.Bool
+
+// ClassifyContextErrors turns the classification of context errors on or off
+//
+// Classification is on by default. Errors without any codes that are, or
+// wrap, context.Canceled or context.DeadlineExceeded are treated as
+// ErrCanceled and ErrDeadlineExceeded by TypeCode(), HTTPCode(), GRPCCode(),
+// Wrap(), Wrapf() and when sent or received over GRPC. Codes set on the error
+// itself always take precedence.
+func ClassifyContextErrors(enabled bool) {
+	contextErrorsIgnored.Store(!enabled)
+}
+
+// classify returns the Error for an uncoded context error when classification is on
+func classify(err error) (Error, bool) {
+	if contextErrorsIgnored.Load() {
+		return ErrUnknown, false
+	}
+	return contextError(err)
+}
+
+// contextError returns the Error for context.Canceled and context.DeadlineExceeded found anywhere in the chain
+func contextError(err error) (Error, bool) {
+	switch {
+	case stderrors.Is(err, context.Canceled):
+		return ErrCanceled, true
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return ErrDeadlineExceeded, true
+	default:
+		return ErrUnknown, false
+	}
+}
@@ -0,0 +1,163 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+var debugInfo atomic.Bool
+
+// EnableDebugInfo turns the capturing of stacks and sending of DebugInfo details on or off
+//
+// Debug info is off by default and should only be enabled in non-production
+// environments; it reveals the internals of the server to its clients. While
+// enabled, stacks are captured when errors are created by this package and
+// every status will include a google.rpc.DebugInfo detail with the stack and
+// the messages from the cause chain.
+func EnableDebugInfo(enabled bool) {
+	debugInfo.Store(enabled)
+}
+
+// DebugInfo is the cause chain and stack of an error
+type DebugInfo struct {
+	Causes []string // messages of every error in the chain, outermost first
+	Stack  []string // frames from where the innermost error was created
+}
+
+// WithStack captures the current stack and attaches it to the error
+//
+// The stack is captured even when debug info has not been enabled.
+// If err is nil then WithStack returns nil
+func WithStack(err error) error {
+	return attach(err, callers(3))
+}
+
+// Debug returns the cause chain and captured stack for the error
+//
+// Received errors return the debug info sent by the server when there was any.
+func Debug(err error) (DebugInfo, bool) {
+	if err == nil {
+		return DebugInfo{}, false
+	}
+
+	if e, ok := err.(*grpcError); ok {
+		if e.debug == nil {
+			return DebugInfo{}, false
+		}
+		return *e.debug, true
+	}
+
+	var info DebugInfo
+	var st *stack
+	seen := map[string]bool{}
+	addCause := func(msg string) {
+		if msg == "" || seen[msg] {
+			return
+		}
+		seen[msg] = true
+		info.Causes = append(info.Causes, msg)
+	}
+
+	walk(err, func(err error) bool {
+		switch err.(type) {
+		case Error:
+			// bare type codes, such as those overriding the type of a wrapped error, are not causes
+		case detailedError:
+			// attached details share the message of the error they are attached to
+		default:
+			addCause(err.Error())
+		}
+		switch e := err.(type) {
+		case embeddedError:
+			if e.st != nil {
+				st = e.st
+			}
+		case detailedError:
+			if s, ok := e.d.(*stack); ok {
+				st = s
+			}
+		case *grpcError:
+			// continue the chain with what the upstream server sent
+			if e.debug != nil {
+				for _, cause := range e.debug.Causes {
+					addCause(cause)
+				}
+				if st == nil {
+					info.Stack = e.debug.Stack
+				}
+			}
+		}
+		return true
+	})
+
+	if st != nil {
+		info.Stack = st.frames()
+	}
+
+	return info, true
+}
+
+type stack []uintptr
+
+// captureStack returns the stack of the caller's caller when debug info is enabled
+func captureStack() *stack {
+	if !debugInfo.Load() {
+		return nil
+	}
+	return callers(4)
+}
+
+func callers(skip int) *stack {
+	const depth = 32
+	var pcs [depth]uintptr
+	n := runtime.Callers(skip, pcs[:])
+	var st stack = pcs[0:n]
+	return &st
+}
+
+// frames formats each frame as "function file:line"
+func (s *stack) frames() []string {
+	if len(*s) == 0 {
+		return nil
+	}
+	frames := runtime.CallersFrames(*s)
+	var entries []string
+	for {
+		frame, more := frames.Next()
+		entries = append(entries, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+	return entries
+}
+
+var (
+	causeEscaper   = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	causeUnescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n")
+)
+
+// join the causes into the single detail string used by google.rpc.DebugInfo
+//
+// Each cause is put on its own line; backslashes and newlines within a cause
+// are escaped so that multi-line messages survive the round trip.
+func joinCauses(causes []string) string {
+	escaped := make([]string, len(causes))
+	for i, cause := range causes {
+		escaped[i] = causeEscaper.Replace(cause)
+	}
+	return strings.Join(escaped, "\n")
+}
+
+func splitCauses(detail string) []string {
+	if detail == "" {
+		return nil
+	}
+	causes := strings.Split(detail, "\n")
+	for i, cause := range causes {
+		causes[i] = causeUnescaper.Replace(cause)
+	}
+	return causes
+}
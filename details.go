@@ -0,0 +1,54 @@
+package errors
+
+import (
+	"google.golang.org/grpc/status"
+)
+
+// detailedError attaches a piece of supplementary information to an error
+// while leaving the message, codes, Is() and As() functionality unchanged
+type detailedError struct {
+	e error       // original error to be embedded
+	d interface{} // the attached detail; must be comparable
+}
+
+func (e detailedError) Error() string {
+	return e.e.Error()
+}
+
+func (e detailedError) Unwrap() error {
+	return e.e
+}
+
+func (e detailedError) GRPCStatus() *status.Status {
+	return ToStatus(e)
+}
+
+// attach returns err with the detail attached or nil when err is nil
+func attach(err error, detail interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return detailedError{e: err, d: detail}
+}
+
+// walk visits err and every error it wraps depth first, outermost first,
+// until fn returns false
+func walk(err error, fn func(error) bool) bool {
+	if err == nil {
+		return true
+	}
+	if !fn(err) {
+		return false
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return walk(e.Unwrap(), fn)
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			if !walk(err, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -16,8 +16,10 @@
 // The errors produced with wrap, that have also been wrapped first with an Err* can be
 // send with SendGRPCError() and received with ReceiveGRPCError().
 //
-// You may want to create and use GRPC server and client interceptors to avoid having to
-// call the Send/Receive methods in every handler.
+// UnaryServerInterceptor() and StreamServerInterceptor() will call SendGRPCError() for
+// every error returned by your handlers, and UnaryClientInterceptor() and
+// StreamClientInterceptor() will call ReceiveGRPCError() for every error returned to
+// your clients.
 //
 // The Err* constants are errors and can be used directly is desired.
 package errors
@@ -35,17 +35,17 @@ func (e Error) Err(err error) error {
 	if err == nil {
 		return nil
 	}
-	return embeddedError{te: e, e: err, msg: err.Error()}
+	return embeddedError{te: e, e: err, msg: err.Error(), st: captureStack()}
 }
 
 // Msg sets a custom message for the Error
 func (e Error) Msg(msg string) error {
-	return embeddedError{e: e, msg: msg}
+	return embeddedError{e: e, msg: msg, st: captureStack()}
 }
 
 // Msgf sets a custom message for formatting for the Error
 func (e Error) Msgf(format string, args ...interface{}) error {
-	return embeddedError{e: e, msg: fmt.Sprintf(format, args...)}
+	return embeddedError{e: e, msg: fmt.Sprintf(format, args...), st: captureStack()}
 }
 
 // Wrap an error with message while overriding or adding Type,HTTP,GRPC information
@@ -54,7 +54,7 @@ func (e Error) Wrap(err error, msg string) error {
 	if err == nil {
 		return nil
 	}
-	return embeddedError{te: e, e: err, msg: msg}
+	return embeddedError{te: e, e: err, msg: msg, st: captureStack()}
 }
 
 // Wrapf an error with message while overriding or adding Type,HTTP,GRPC information
@@ -63,13 +63,14 @@ func (e Error) Wrapf(err error, format string, args ...interface{}) error {
 	if err == nil {
 		return nil
 	}
-	return embeddedError{te: e, e: err, msg: fmt.Sprintf(format, args...)}
+	return embeddedError{te: e, e: err, msg: fmt.Sprintf(format, args...), st: captureStack()}
 }
 
 type embeddedError struct {
 	e   error  // original error to be embedded
 	te  error  // overriding error type
 	msg string // for the humans
+	st  *stack // where the error was created when debug info is enabled
 }
 
 func (e embeddedError) Error() string {
@@ -107,19 +108,32 @@ func (e embeddedError) As(target interface{}) bool {
 	return false
 }
 
+// Unwrap returns the overriding error type followed by the original error
+func (e embeddedError) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	if e.te != nil {
+		errs = append(errs, e.te)
+	}
+	if e.e != nil {
+		errs = append(errs, e.e)
+	}
+	return errs
+}
+
 // Wrap returns an error with msg wrapped with the supplied error
 // If err is nil then Wrap returns nil
 func Wrap(err error, msg string) error {
 	if err == nil {
 		return nil
 	}
-	switch err.(type) {
+	// attached details do not change how the error is wrapped
+	switch inner := undetailed(err); inner.(type) {
 	case embeddedError:
-		return embeddedError{e: err, msg: fmt.Sprintf("%s: %s", msg, err.Error())}
+		return embeddedError{e: err, msg: fmt.Sprintf("%s: %s", msg, err.Error()), st: captureStack()}
 	case TypeCoder:
-		return embeddedError{te: err, msg: msg}
+		return embeddedError{te: err, msg: msg, st: captureStack()}
 	default:
-		return embeddedError{e: err, te: ErrInternalServerError, msg: fmt.Sprintf("%s: %s", msg, err.Error())}
+		return embeddedError{e: err, te: uncodedType(inner), msg: fmt.Sprintf("%s: %s", msg, err.Error()), st: captureStack()}
 	}
 }
 
@@ -129,18 +143,39 @@ func Wrapf(err error, format string, args ...interface{}) error {
 	if err == nil {
 		return nil
 	}
-	switch err.(type) {
+	// attached details do not change how the error is wrapped
+	switch inner := undetailed(err); inner.(type) {
 	case embeddedError:
-		return embeddedError{e: err, msg: fmt.Sprintf("%s: %s", fmt.Sprintf(format, args...), err.Error())}
+		return embeddedError{e: err, msg: fmt.Sprintf("%s: %s", fmt.Sprintf(format, args...), err.Error()), st: captureStack()}
 	case TypeCoder:
-		return embeddedError{te: err, msg: fmt.Sprintf(format, args...)}
+		return embeddedError{te: err, msg: fmt.Sprintf(format, args...), st: captureStack()}
 	default:
 		return embeddedError{
 			e:   err,
-			te:  ErrInternalServerError,
+			te:  uncodedType(inner),
 			msg: fmt.Sprintf("%s: %s", fmt.Sprintf(format, args...), err.Error()),
+			st:  captureStack(),
+		}
+	}
+}
+
+// undetailed returns the error that details were attached to
+func undetailed(err error) error {
+	for {
+		d, ok := err.(detailedError)
+		if !ok {
+			return err
 		}
+		err = d.e
+	}
+}
+
+// uncodedType returns the type used when wrapping errors without any codes
+func uncodedType(err error) Error {
+	if ce, ok := classify(err); ok {
+		return ce
 	}
+	return ErrInternalServerError
 }
 
 // TypeCode returns the embedded type for the given error or blank when nil or UNKNOWN otherwise
@@ -153,6 +188,9 @@ func TypeCode(err error) string {
 	if stderrors.As(err, &e) {
 		return e.TypeCode()
 	}
+	if ce, ok := classify(err); ok {
+		return ce.TypeCode()
+	}
 	return ErrUnknown.TypeCode()
 }
 
@@ -0,0 +1,75 @@
+Feature: Status budget
+  Statuses are kept small enough to be sent in the GRPC trailers
+
+  Scenario: small errors are sent whole
+    Given the error is "ErrNotFound"
+    When wrapped with the message "record missing"
+    And the error is sent over GRPC
+    Then the error is not truncated
+    And the error message is "record missing"
+
+  Scenario: long messages are shortened
+    Given the error is "ErrNotFound"
+    When wrapped with a message of 20000 bytes
+    And the error is returned by a unary GRPC handler
+    Then the GRPC code is "NotFound"
+    And the Type code is "NOT_FOUND"
+    And the error is truncated
+    And the error message is shorter than 7168 bytes
+    And the error message ends with "..."
+
+  Scenario: non-ASCII messages are counted as they are sent
+    Given the error is "ErrNotFound"
+    When wrapped with a non-ASCII message of 3000 bytes
+    And the error is sent over GRPC
+    Then the error is truncated
+    And the error message is shorter than 1700 bytes
+    And the error message ends with "..."
+
+  Scenario: debug info is dropped before help links
+    Given debug info is enabled
+    And the error is "ErrNotFound"
+    And the help link "https://example.com/errors/not-found" is attached
+    When wrapped with the message "record missing"
+    And the error is sent over GRPC with a budget of 600 bytes
+    Then the error is truncated
+    And the error has no debug info
+    And the help links include "https://example.com/errors/not-found"
+    And the error message is "record missing"
+
+  Scenario: oversized fields are dropped
+    Given the error is "ErrNotFound"
+    And the error has the field "region" with the value "us-east-1"
+    And the error has the field "query" with a value of 8000 bytes
+    When wrapped with the message "record missing"
+    And the error is sent over GRPC
+    Then the error is truncated
+    And the field "query" is ""
+    And the field "region" is ""
+    And the error message is "record missing"
+
+  Scenario: long public messages are shortened
+    Given the error is "ErrNotFound"
+    And the error has a public message of 8000 bytes
+    When wrapped with the message "record missing"
+    And the error is sent over GRPC
+    Then the error is truncated
+    And the public message is shorter than 7168 bytes
+    And the public message ends with "..."
+    And the error message is "record missing"
+
+  Scenario: the budget can be changed
+    Given the status budget is 1024 bytes
+    And the error is "ErrNotFound"
+    When wrapped with a message of 2000 bytes
+    And the error is sent over GRPC
+    Then the error is truncated
+    And the error message is shorter than 1024 bytes
+
+  Scenario: the budget can be turned off
+    Given the status budget is 0 bytes
+    And the error is "ErrNotFound"
+    When wrapped with a message of 20000 bytes
+    And the error is sent over GRPC
+    Then the error is not truncated
+    And the error message ends with "xxx"
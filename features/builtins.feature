@@ -7,36 +7,154 @@ Feature: Builtin errors have all the right codes
     And the GRPC code is "<grpc code>"
 
     Scenarios:
-      | error                         | type code                     | http code                     | grpc code          |
-      | ErrOK                         |                               | OK                            | OK                 |
-      | ErrCanceled                   | CANCELED                      | Request Timeout               | Canceled           |
-      | ErrUnknown                    | UNKNOWN                       | Not Extended                  | Unknown            |
-      | ErrInvalidArgument            | INVALID_ARGUMENT              | Bad Request                   | InvalidArgument    |
-      | ErrDeadlineExceeded           | DEADLINE_EXCEEDED             | Gateway Timeout               | DeadlineExceeded   |
-      | ErrNotFound                   | NOT_FOUND                     | Not Found                     | NotFound           |
-      | ErrAlreadyExists              | ALREADY_EXISTS                | Conflict                      | AlreadyExists      |
-      | ErrPermissionDenied           | PERMISSION_DENIED             | Forbidden                     | PermissionDenied   |
-      | ErrResourceExhausted          | RESOURCE_EXHAUSTED            | Too Many Requests             | ResourceExhausted  |
-      | ErrFailedPrecondition         | FAILED_PRECONDITION           | Bad Request                   | FailedPrecondition |
-      | ErrAborted                    | ABORTED                       | Conflict                      | Aborted            |
-      | ErrOutOfRange                 | OUT_OF_RANGE                  | Unprocessable Entity          | OutOfRange         |
-      | ErrUnimplemented              | UNIMPLEMENTED                 | Not Implemented               | Unimplemented      |
-      | ErrInternal                   | INTERNAL                      | Internal Server Error         | Internal           |
-      | ErrUnavailable                | UNAVAILABLE                   | Service Unavailable           | Unavailable        |
-      | ErrDataLoss                   | DATA_LOSS                     | Internal Server Error         | DataLoss           |
-      | ErrUnauthenticated            | UNAUTHENTICATED               | Unauthorized                  | Unauthenticated    |
-      | ErrBadRequest                 | BAD_REQUEST                   | Bad Request                   | InvalidArgument    |
-      | ErrUnauthorized               | UNAUTHORIZED                  | Unauthorized                  | Unauthenticated    |
-      | ErrForbidden                  | FORBIDDEN                     | Forbidden                     | PermissionDenied   |
-      | ErrMethodNotAllowed           | METHOD_NOT_ALLOWED            | Method Not Allowed            | Unimplemented      |
-      | ErrRequestTimeout             | REQUEST_TIMEOUT               | Request Timeout               | DeadlineExceeded   |
-      | ErrConflict                   | CONFLICT                      | Conflict                      | AlreadyExists      |
-      | ErrImATeapot                  | IM_A_TEAPOT                   | I'm a teapot                  | Unknown            |
-      | ErrUnprocessableEntity        | UNPROCESSABLE_ENTITY          | Unprocessable Entity          | InvalidArgument    |
-      | ErrTooManyRequests            | TOO_MANY_REQUESTS             | Too Many Requests             | ResourceExhausted  |
-      | ErrUnavailableForLegalReasons | UNAVAILABLE_FOR_LEGAL_REASONS | Unavailable For Legal Reasons | Unavailable        |
-      | ErrInternalServerError        | INTERNAL_SERVER_ERROR         | Internal Server Error         | Internal           |
-      | ErrNotImplemented             | NOT_IMPLEMENTED               | Not Implemented               | Unimplemented      |
-      | ErrBadGateway                 | BAD_GATEWAY                   | Bad Gateway                   | Aborted            |
-      | ErrServiceUnavailable         | SERVICE_UNAVAILABLE           | Service Unavailable           | Unavailable        |
-      | ErrGatewayTimeout             | GATEWAY_TIMEOUT               | Gateway Timeout               | DeadlineExceeded   |
+      | error                            | type code                       | http code                       | grpc code          |
+      | ErrOK                            |                                 | OK                              | OK                 |
+      | ErrCanceled                      | CANCELED                        | Request Timeout                 | Canceled           |
+      | ErrUnknown                       | UNKNOWN                         | Not Extended                    | Unknown            |
+      | ErrInvalidArgument               | INVALID_ARGUMENT                | Bad Request                     | InvalidArgument    |
+      | ErrDeadlineExceeded              | DEADLINE_EXCEEDED               | Gateway Timeout                 | DeadlineExceeded   |
+      | ErrNotFound                      | NOT_FOUND                       | Not Found                       | NotFound           |
+      | ErrAlreadyExists                 | ALREADY_EXISTS                  | Conflict                        | AlreadyExists      |
+      | ErrPermissionDenied              | PERMISSION_DENIED               | Forbidden                       | PermissionDenied   |
+      | ErrResourceExhausted             | RESOURCE_EXHAUSTED              | Too Many Requests               | ResourceExhausted  |
+      | ErrFailedPrecondition            | FAILED_PRECONDITION             | Bad Request                     | FailedPrecondition |
+      | ErrAborted                       | ABORTED                         | Conflict                        | Aborted            |
+      | ErrOutOfRange                    | OUT_OF_RANGE                    | Unprocessable Entity            | OutOfRange         |
+      | ErrUnimplemented                 | UNIMPLEMENTED                   | Not Implemented                 | Unimplemented      |
+      | ErrInternal                      | INTERNAL                        | Internal Server Error           | Internal           |
+      | ErrUnavailable                   | UNAVAILABLE                     | Service Unavailable             | Unavailable        |
+      | ErrDataLoss                      | DATA_LOSS                       | Internal Server Error           | DataLoss           |
+      | ErrUnauthenticated               | UNAUTHENTICATED                 | Unauthorized                    | Unauthenticated    |
+      | ErrBadRequest                    | BAD_REQUEST                     | Bad Request                     | InvalidArgument    |
+      | ErrUnauthorized                  | UNAUTHORIZED                    | Unauthorized                    | Unauthenticated    |
+      | ErrPaymentRequired               | PAYMENT_REQUIRED                | Payment Required                | FailedPrecondition |
+      | ErrForbidden                     | FORBIDDEN                       | Forbidden                       | PermissionDenied   |
+      | ErrMethodNotAllowed              | METHOD_NOT_ALLOWED              | Method Not Allowed              | Unimplemented      |
+      | ErrNotAcceptable                 | NOT_ACCEPTABLE                  | Not Acceptable                  | InvalidArgument    |
+      | ErrProxyAuthRequired             | PROXY_AUTH_REQUIRED             | Proxy Authentication Required   | Unauthenticated    |
+      | ErrRequestTimeout                | REQUEST_TIMEOUT                 | Request Timeout                 | DeadlineExceeded   |
+      | ErrConflict                      | CONFLICT                        | Conflict                        | AlreadyExists      |
+      | ErrGone                          | GONE                            | Gone                            | NotFound           |
+      | ErrLengthRequired                | LENGTH_REQUIRED                 | Length Required                 | InvalidArgument    |
+      | ErrPreconditionFailed            | PRECONDITION_FAILED             | Precondition Failed             | FailedPrecondition |
+      | ErrPayloadTooLarge               | PAYLOAD_TOO_LARGE               | Request Entity Too Large        | InvalidArgument    |
+      | ErrURITooLong                    | URI_TOO_LONG                    | Request URI Too Long            | InvalidArgument    |
+      | ErrUnsupportedMediaType          | UNSUPPORTED_MEDIA_TYPE          | Unsupported Media Type          | InvalidArgument    |
+      | ErrRangeNotSatisfiable           | RANGE_NOT_SATISFIABLE           | Requested Range Not Satisfiable | OutOfRange         |
+      | ErrExpectationFailed             | EXPECTATION_FAILED              | Expectation Failed              | FailedPrecondition |
+      | ErrImATeapot                     | IM_A_TEAPOT                     | I'm a teapot                    | Unknown            |
+      | ErrMisdirectedRequest            | MISDIRECTED_REQUEST             | Misdirected Request             | Unavailable        |
+      | ErrUnprocessableEntity           | UNPROCESSABLE_ENTITY            | Unprocessable Entity            | InvalidArgument    |
+      | ErrLocked                        | LOCKED                          | Locked                          | FailedPrecondition |
+      | ErrFailedDependency              | FAILED_DEPENDENCY               | Failed Dependency               | FailedPrecondition |
+      | ErrTooEarly                      | TOO_EARLY                       | Too Early                       | Unavailable        |
+      | ErrUpgradeRequired               | UPGRADE_REQUIRED                | Upgrade Required                | FailedPrecondition |
+      | ErrPreconditionRequired          | PRECONDITION_REQUIRED           | Precondition Required           | FailedPrecondition |
+      | ErrTooManyRequests               | TOO_MANY_REQUESTS               | Too Many Requests               | ResourceExhausted  |
+      | ErrRequestHeaderFieldsTooLarge   | REQUEST_HEADER_FIELDS_TOO_LARGE | Request Header Fields Too Large | InvalidArgument    |
+      | ErrUnavailableForLegalReasons    | UNAVAILABLE_FOR_LEGAL_REASONS   | Unavailable For Legal Reasons   | Unavailable        |
+      | ErrInternalServerError           | INTERNAL_SERVER_ERROR           | Internal Server Error           | Internal           |
+      | ErrNotImplemented                | NOT_IMPLEMENTED                 | Not Implemented                 | Unimplemented      |
+      | ErrBadGateway                    | BAD_GATEWAY                     | Bad Gateway                     | Aborted            |
+      | ErrServiceUnavailable            | SERVICE_UNAVAILABLE             | Service Unavailable             | Unavailable        |
+      | ErrGatewayTimeout                | GATEWAY_TIMEOUT                 | Gateway Timeout                 | DeadlineExceeded   |
+      | ErrHTTPVersionNotSupported       | HTTP_VERSION_NOT_SUPPORTED      | HTTP Version Not Supported      | Unimplemented      |
+      | ErrVariantAlsoNegotiates         | VARIANT_ALSO_NEGOTIATES         | Variant Also Negotiates         | Internal           |
+      | ErrInsufficientStorage           | INSUFFICIENT_STORAGE            | Insufficient Storage            | ResourceExhausted  |
+      | ErrLoopDetected                  | LOOP_DETECTED                   | Loop Detected                   | Internal           |
+      | ErrNetworkAuthenticationRequired | NETWORK_AUTHENTICATION_REQUIRED | Network Authentication Required | Unauthenticated    |
+
+  Scenario Outline: HTTP errors keep their codes over GRPC
+    Given the error is "<error>"
+    When the error is sent over GRPC
+    Then the Type code is "<type code>"
+    And the HTTP status is "<http code>"
+    And the GRPC code is "<grpc code>"
+    And the error is a "<error>"
+
+    Scenarios:
+      | error                            | type code                       | http code                       | grpc code          |
+      | ErrPaymentRequired               | PAYMENT_REQUIRED                | Payment Required                | FailedPrecondition |
+      | ErrNotAcceptable                 | NOT_ACCEPTABLE                  | Not Acceptable                  | InvalidArgument    |
+      | ErrProxyAuthRequired             | PROXY_AUTH_REQUIRED             | Proxy Authentication Required   | Unauthenticated    |
+      | ErrLengthRequired                | LENGTH_REQUIRED                 | Length Required                 | InvalidArgument    |
+      | ErrPreconditionFailed            | PRECONDITION_FAILED             | Precondition Failed             | FailedPrecondition |
+      | ErrPayloadTooLarge               | PAYLOAD_TOO_LARGE               | Request Entity Too Large        | InvalidArgument    |
+      | ErrURITooLong                    | URI_TOO_LONG                    | Request URI Too Long            | InvalidArgument    |
+      | ErrRangeNotSatisfiable           | RANGE_NOT_SATISFIABLE           | Requested Range Not Satisfiable | OutOfRange         |
+      | ErrExpectationFailed             | EXPECTATION_FAILED              | Expectation Failed              | FailedPrecondition |
+      | ErrMisdirectedRequest            | MISDIRECTED_REQUEST             | Misdirected Request             | Unavailable        |
+      | ErrLocked                        | LOCKED                          | Locked                          | FailedPrecondition |
+      | ErrFailedDependency              | FAILED_DEPENDENCY               | Failed Dependency               | FailedPrecondition |
+      | ErrTooEarly                      | TOO_EARLY                       | Too Early                       | Unavailable        |
+      | ErrUpgradeRequired               | UPGRADE_REQUIRED                | Upgrade Required                | FailedPrecondition |
+      | ErrPreconditionRequired          | PRECONDITION_REQUIRED           | Precondition Required           | FailedPrecondition |
+      | ErrRequestHeaderFieldsTooLarge   | REQUEST_HEADER_FIELDS_TOO_LARGE | Request Header Fields Too Large | InvalidArgument    |
+      | ErrHTTPVersionNotSupported       | HTTP_VERSION_NOT_SUPPORTED      | HTTP Version Not Supported      | Unimplemented      |
+      | ErrVariantAlsoNegotiates         | VARIANT_ALSO_NEGOTIATES         | Variant Also Negotiates         | Internal           |
+      | ErrInsufficientStorage           | INSUFFICIENT_STORAGE            | Insufficient Storage            | ResourceExhausted  |
+      | ErrLoopDetected                  | LOOP_DETECTED                   | Loop Detected                   | Internal           |
+      | ErrNetworkAuthenticationRequired | NETWORK_AUTHENTICATION_REQUIRED | Network Authentication Required | Unauthenticated    |
+
+  Scenario Outline: HTTP errors keep their codes over HTTP
+    Given the error is "<error>"
+    When the error is written as a problem
+    And the response is received
+    Then the Type code is "<type code>"
+    And the HTTP status is "<http code>"
+    And the GRPC code is "<grpc code>"
+    And the error is a "<error>"
+
+    Scenarios:
+      | error                            | type code                       | http code                       | grpc code          |
+      | ErrPaymentRequired               | PAYMENT_REQUIRED                | Payment Required                | FailedPrecondition |
+      | ErrNotAcceptable                 | NOT_ACCEPTABLE                  | Not Acceptable                  | InvalidArgument    |
+      | ErrProxyAuthRequired             | PROXY_AUTH_REQUIRED             | Proxy Authentication Required   | Unauthenticated    |
+      | ErrLengthRequired                | LENGTH_REQUIRED                 | Length Required                 | InvalidArgument    |
+      | ErrPreconditionFailed            | PRECONDITION_FAILED             | Precondition Failed             | FailedPrecondition |
+      | ErrPayloadTooLarge               | PAYLOAD_TOO_LARGE               | Request Entity Too Large        | InvalidArgument    |
+      | ErrURITooLong                    | URI_TOO_LONG                    | Request URI Too Long            | InvalidArgument    |
+      | ErrRangeNotSatisfiable           | RANGE_NOT_SATISFIABLE           | Requested Range Not Satisfiable | OutOfRange         |
+      | ErrExpectationFailed             | EXPECTATION_FAILED              | Expectation Failed              | FailedPrecondition |
+      | ErrMisdirectedRequest            | MISDIRECTED_REQUEST             | Misdirected Request             | Unavailable        |
+      | ErrLocked                        | LOCKED                          | Locked                          | FailedPrecondition |
+      | ErrFailedDependency              | FAILED_DEPENDENCY               | Failed Dependency               | FailedPrecondition |
+      | ErrTooEarly                      | TOO_EARLY                       | Too Early                       | Unavailable        |
+      | ErrUpgradeRequired               | UPGRADE_REQUIRED                | Upgrade Required                | FailedPrecondition |
+      | ErrPreconditionRequired          | PRECONDITION_REQUIRED           | Precondition Required           | FailedPrecondition |
+      | ErrRequestHeaderFieldsTooLarge   | REQUEST_HEADER_FIELDS_TOO_LARGE | Request Header Fields Too Large | InvalidArgument    |
+      | ErrHTTPVersionNotSupported       | HTTP_VERSION_NOT_SUPPORTED      | HTTP Version Not Supported      | Unimplemented      |
+      | ErrVariantAlsoNegotiates         | VARIANT_ALSO_NEGOTIATES         | Variant Also Negotiates         | Internal           |
+      | ErrInsufficientStorage           | INSUFFICIENT_STORAGE            | Insufficient Storage            | ResourceExhausted  |
+      | ErrLoopDetected                  | LOOP_DETECTED                   | Loop Detected                   | Internal           |
+      | ErrNetworkAuthenticationRequired | NETWORK_AUTHENTICATION_REQUIRED | Network Authentication Required | Unauthenticated    |
+
+  Scenario Outline: bare HTTP statuses are received as the HTTP errors
+    Given the server responds with the status <status>
+    When the response is received
+    Then the error is a "<error>"
+    And the Type code is "<type code>"
+
+    Scenarios:
+      | status | error                            | type code                       |
+      | 402    | ErrPaymentRequired               | PAYMENT_REQUIRED                |
+      | 406    | ErrNotAcceptable                 | NOT_ACCEPTABLE                  |
+      | 407    | ErrProxyAuthRequired             | PROXY_AUTH_REQUIRED             |
+      | 411    | ErrLengthRequired                | LENGTH_REQUIRED                 |
+      | 412    | ErrPreconditionFailed            | PRECONDITION_FAILED             |
+      | 413    | ErrPayloadTooLarge               | PAYLOAD_TOO_LARGE               |
+      | 414    | ErrURITooLong                    | URI_TOO_LONG                    |
+      | 416    | ErrRangeNotSatisfiable           | RANGE_NOT_SATISFIABLE           |
+      | 417    | ErrExpectationFailed             | EXPECTATION_FAILED              |
+      | 421    | ErrMisdirectedRequest            | MISDIRECTED_REQUEST             |
+      | 423    | ErrLocked                        | LOCKED                          |
+      | 424    | ErrFailedDependency              | FAILED_DEPENDENCY               |
+      | 425    | ErrTooEarly                      | TOO_EARLY                       |
+      | 426    | ErrUpgradeRequired               | UPGRADE_REQUIRED                |
+      | 428    | ErrPreconditionRequired          | PRECONDITION_REQUIRED           |
+      | 431    | ErrRequestHeaderFieldsTooLarge   | REQUEST_HEADER_FIELDS_TOO_LARGE |
+      | 505    | ErrHTTPVersionNotSupported       | HTTP_VERSION_NOT_SUPPORTED      |
+      | 506    | ErrVariantAlsoNegotiates         | VARIANT_ALSO_NEGOTIATES         |
+      | 507    | ErrInsufficientStorage           | INSUFFICIENT_STORAGE            |
+      | 508    | ErrLoopDetected                  | LOOP_DETECTED                   |
+      | 511    | ErrNetworkAuthenticationRequired | NETWORK_AUTHENTICATION_REQUIRED |
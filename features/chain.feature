@@ -0,0 +1,23 @@
+Feature: Coded cause chains
+  Every coded layer of an error can be sent over GRPC
+
+  Scenario: only the outermost codes are sent by default
+    Given the error is "ErrDeadlineExceeded"
+    When wrapped with the message "query timed out"
+    And wrapped with the error "ErrUnavailable" and message "inventory is unavailable"
+    And the error is sent over GRPC
+    Then the Type code is "UNAVAILABLE"
+    And the error is a "ErrUnavailable"
+    And the error is not a "ErrDeadlineExceeded"
+
+  Scenario: the cause chain can be sent
+    Given the error is "ErrDeadlineExceeded"
+    When wrapped with the message "query timed out"
+    And wrapped with the error "ErrUnavailable" and message "inventory is unavailable"
+    And the error is sent over GRPC with the cause chain
+    Then the Type code is "UNAVAILABLE"
+    And the GRPC code is "Unavailable"
+    And the error message is "inventory is unavailable"
+    And the error is a "ErrUnavailable"
+    And the error is a "ErrDeadlineExceeded"
+    And the error is not a "ErrNotFound"
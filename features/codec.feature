@@ -0,0 +1,11 @@
+Feature: Registered details
+  Registered error types are sent as details and rebuilt when received
+
+  Scenario: registered error types are rebuilt
+    Given the "user" resource "alice" is wrapped with the error "ErrNotFound"
+    When the error is sent over GRPC
+    Then the error is the "user" resource "alice"
+    And the error is a "ErrNotFound"
+    And the Type code is "NOT_FOUND"
+    And the error message is "resource error"
+
@@ -0,0 +1,54 @@
+Feature: Context errors
+  Canceled and timed out contexts are classified without any extra work
+
+  Scenario Outline: context errors have codes
+    Given the error is the standard error "<error>"
+    Then the Type code is "<type code>"
+    And the HTTP status is "<http status>"
+    And the GRPC code is "<grpc code>"
+
+    Examples:
+      | error                    | type code         | http status     | grpc code        |
+      | context.Canceled         | CANCELED          | Request Timeout | Canceled         |
+      | context.DeadlineExceeded | DEADLINE_EXCEEDED | Gateway Timeout | DeadlineExceeded |
+
+  Scenario: context errors are found anywhere in the chain
+    Given the error is the standard error "context.DeadlineExceeded"
+    When annotated with the message "querying users"
+    Then the Type code is "DEADLINE_EXCEEDED"
+    And the GRPC code is "DeadlineExceeded"
+
+  Scenario: wrapped context errors keep their classification
+    Given the error is the standard error "context.Canceled"
+    When wrapped with the message "reading request"
+    Then the Type code is "CANCELED"
+    And the error is a "ErrCanceled"
+    And the error wraps "context.Canceled"
+    And the error message is "reading request: context canceled"
+
+  Scenario: context errors are sent with their codes
+    Given the error is the standard error "context.DeadlineExceeded"
+    When the error is sent over GRPC
+    Then the GRPC code is "DeadlineExceeded"
+    And the Type code is "DEADLINE_EXCEEDED"
+    And the error is a "ErrDeadlineExceeded"
+
+  Scenario: codes on the error take precedence
+    Given the error is the standard error "context.Canceled"
+    When wrapped with the error "ErrUnavailable" and message "backend is gone"
+    Then the Type code is "UNAVAILABLE"
+    And the GRPC code is "Unavailable"
+
+  Scenario: classification can be turned off
+    Given context classification is disabled
+    And the error is the standard error "context.Canceled"
+    When wrapped with the message "reading request"
+    Then the Type code is "INTERNAL_SERVER_ERROR"
+    And the error is not a "ErrCanceled"
+
+  Scenario: unclassified context errors are unknown
+    Given context classification is disabled
+    And the error is the standard error "context.DeadlineExceeded"
+    When the error is sent over GRPC
+    Then the GRPC code is "Unknown"
+    And the Type code is "UNKNOWN"
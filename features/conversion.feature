@@ -0,0 +1,48 @@
+Feature: Converting errors and statuses
+  Errors can be converted to a *status.Status and back without sending them
+
+  Scenario: errors are converted to statuses with their codes
+    Given the error is "ErrNotFound"
+    When wrapped with the message "record missing"
+    And the error is converted to a status
+    Then the status code is "NotFound"
+    And the status message is "record missing"
+
+  Scenario: statuses are converted back into errors
+    Given the error is "ErrAlreadyExists"
+    And the error has the ID "req-42"
+    When the error is converted to a status
+    And the status is converted to an error
+    Then the Type code is "ALREADY_EXISTS"
+    And the HTTP status is "Conflict"
+    And the ID is "req-42"
+    And the error is a "ErrAlreadyExists"
+
+  Scenario: OK statuses are not errors
+    Given an OK status is converted
+    When the status is converted to an error
+    Then the status is not an error
+
+  Scenario: errors without codes use the default type
+    Given the error does not implement GRPCCoder{}
+    When the error is converted to a status with the default type "ErrInternal"
+    And the status is converted to an error
+    Then the GRPC code is "Internal"
+    And the Type code is "INTERNAL"
+
+  Scenario: the default type does not replace codes
+    Given the error is "ErrNotFound"
+    When the error is converted to a status with the default type "ErrInternal"
+    Then the status code is "NotFound"
+
+  Scenario: status messages can be masked
+    Given the error is "ErrInternal"
+    When wrapped with the message "pq: connection refused"
+    And the error is converted to a status with masking
+    Then the status code is "Internal"
+    And the status message is "Internal Server Error"
+
+  Scenario: detail encoders add details to the status
+    Given the error is "ErrNotFound"
+    When the error is converted to a status with an ErrorInfo encoder
+    Then the status has an ErrorInfo with the reason "NOT_FOUND"
@@ -0,0 +1,41 @@
+Feature: Debug info
+  Errors can carry their cause chain and stack to clients outside of production
+
+  Scenario: debug info is not sent by default
+    Given the error is "ErrNotFound"
+    When wrapped with the message "record missing"
+    And the error is sent over GRPC
+    Then the error has no debug info
+
+  Scenario: debug info can be sent over GRPC
+    Given debug info is enabled
+    And the error is "ErrNotFound"
+    When wrapped with the message "record missing"
+    And wrapped with the error "ErrUnavailable" and message "lookup failed"
+    And the error is sent over GRPC
+    Then the debug causes are "lookup failed, record missing"
+    And the debug stack includes "wrappedWithTheMessage"
+    And the error message is "lookup failed"
+
+  Scenario: multi-line causes are kept whole over GRPC
+    Given debug info is enabled
+    And the error is "ErrNotFound"
+    When wrapped with the message:
+      """
+      record missing
+      in table \users\
+      """
+    And wrapped with the error "ErrUnavailable" and message "lookup failed"
+    And the error is sent over GRPC
+    Then the debug causes include:
+      """
+      record missing
+      in table \users\
+      """
+    And the debug causes include "lookup failed"
+
+  Scenario: type codes are not causes
+    Given debug info is enabled
+    And the error is the standard error "io.EOF"
+    When wrapped with the error "ErrNotFound" and message "read failed"
+    Then the debug causes are "read failed, EOF"
@@ -0,0 +1,126 @@
+Feature: GraphQL errors
+  Errors can be formatted as GraphQL error objects and parsed back
+
+  Scenario: the codes are extensions
+    Given the error is "ErrNotFound"
+    When wrapped with the message "user 42 not found"
+    And the error has the ID "req-42"
+    And the error has the field "parameter" with the value "id"
+    And the error has the public message "We could not find that user"
+    And the error is formatted for GraphQL
+    Then the GraphQL error has the "message" "We could not find that user"
+    And the GraphQL error has the "extensions.code" "NOT_FOUND"
+    And the GraphQL error has the "extensions.httpCode" "404"
+    And the GraphQL error has the "extensions.grpcCode" "5"
+    And the GraphQL error has the "extensions.id" "req-42"
+    And the GraphQL error has the "extensions.fields.parameter" "id"
+
+  Scenario: field violations are extensions
+    Given the error is "ErrInvalidArgument"
+    And the error has the field violation "input.email": "must be an email address"
+    When the error is formatted for GraphQL
+    Then the GraphQL error has the "extensions.fieldViolations.0.field" "input.email"
+    And the GraphQL error has the "extensions.fieldViolations.0.description" "must be an email address"
+
+  Scenario: messages of errors are not written
+    Given the error is "ErrNotFound"
+    When wrapped with the message "internal row id 42"
+    And the error is formatted for GraphQL
+    Then the GraphQL error has the "message" "Not Found"
+
+  Scenario: messages of server faults are not written
+    Given the error is "ErrInternal"
+    When wrapped with the message "pq: connection refused"
+    And the error is formatted for GraphQL
+    Then the GraphQL error has the "message" "Internal Server Error"
+    And the GraphQL error has the "extensions.code" "INTERNAL"
+
+  Scenario: errors received from GRPC keep their codes
+    Given the error is "ErrPermissionDenied"
+    When the error is sent over GRPC
+    And the error is formatted for GraphQL
+    Then the GraphQL error has the "extensions.code" "PERMISSION_DENIED"
+    And the GraphQL error has the "extensions.httpCode" "403"
+    And the GraphQL error has the "extensions.grpcCode" "7"
+
+  Scenario: errors are parsed back
+    Given the error is "ErrNotFound"
+    When wrapped with the message "user 42 not found"
+    And the error has the ID "req-42"
+    And the error has the field "parameter" with the value "id"
+    And the error has the field violation "input.id": "no such user"
+    And the error has the public message "We could not find that user"
+    And the error is formatted for GraphQL
+    And the GraphQL errors are parsed
+    Then the Type code is "NOT_FOUND"
+    And the HTTP status is "Not Found"
+    And the GRPC code is "NotFound"
+    And the error is a "ErrNotFound"
+    And the error message is "We could not find that user"
+    And the ID is "req-42"
+    And the field "parameter" is "id"
+    And field violation 1 is "input.id": "no such user"
+
+  Scenario: custom type codes keep their codes
+    Given an error with Type code "OUT_OF_STOCK"
+    And an error with HTTP status "http.StatusConflict"
+    When the error is formatted for GraphQL
+    And the GraphQL errors are parsed
+    Then the Type code is "OUT_OF_STOCK"
+    And the HTTP status is "Conflict"
+    And the GRPC code is "Unknown"
+
+  Scenario: joined errors are parsed back
+    Given the error is "ErrConflict"
+    And the error is joined with "ErrNotFound" with the message "team not found"
+    When the error is formatted for GraphQL
+    And the GraphQL errors are parsed
+    Then the error is a "ErrConflict"
+    And the error is a "ErrNotFound"
+
+  Scenario: joined errors with details are parsed back
+    Given the error is "ErrConflict"
+    And the error is joined with "ErrNotFound" with the message "team not found"
+    And the error has the ID "req-42"
+    When the error is formatted for GraphQL
+    And the GraphQL errors are parsed
+    Then the error is a "ErrConflict"
+    And the error is a "ErrNotFound"
+    And the ID is "req-42"
+
+  Scenario: errors from other GraphQL servers use their codes
+    Given a GraphQL response with the errors:
+      """
+      {"data": null, "errors": [{"message": "Email is invalid", "path": ["createUser"], "extensions": {"code": "BAD_USER_INPUT"}}]}
+      """
+    When the GraphQL errors are parsed
+    Then the Type code is "BAD_USER_INPUT"
+    And the HTTP status is "Bad Request"
+    And the GRPC code is "InvalidArgument"
+    And the error message is "Email is invalid"
+
+  Scenario: errors with known type codes and no HTTP code use the type
+    Given a GraphQL response with the errors:
+      """
+      {"errors": [{"message": "Sign in first", "extensions": {"code": "UNAUTHENTICATED"}}]}
+      """
+    When the GraphQL errors are parsed
+    Then the error is a "ErrUnauthenticated"
+    And the HTTP status is "Unauthorized"
+
+  Scenario: HTTP codes that are not errors are not trusted
+    Given a GraphQL response with the errors:
+      """
+      {"errors": [{"message": "Not there", "extensions": {"code": "OK", "httpCode": 200}}]}
+      """
+    When the GraphQL errors are parsed
+    Then the HTTP status is "Internal Server Error"
+    And the GRPC code is "Internal"
+
+  Scenario: responses without errors are not errors
+    Given a GraphQL response with the errors:
+      """
+      {"data": {"user": {"id": "42"}}}
+      """
+    When the GraphQL errors are parsed
+    Then the call succeeded
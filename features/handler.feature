@@ -0,0 +1,44 @@
+Feature: HTTP handlers
+  HTTP handlers can return errors which are then written for them
+
+  Scenario: returned errors are written as problems
+    Given the error is "ErrNotFound"
+    When the error is returned by an HTTP handler
+    Then the response status is 404
+    And the response header "Content-Type" is "application/problem+json"
+    And the problem member "code" is "NOT_FOUND"
+
+  Scenario: handler funcs are handlers
+    Given the error is "ErrForbidden"
+    When the error is returned by a HandlerFunc
+    Then the response status is 403
+    And the problem member "code" is "FORBIDDEN"
+
+  Scenario: returned errors are logged
+    Given the error is "ErrConflict"
+    And the server logs errors
+    When the error is returned by an HTTP handler
+    Then the server logged "CONFLICT"
+    And the response status is 409
+
+  Scenario: messages of server faults are not written
+    Given the error is "ErrInternal"
+    When wrapped with the message "pq: connection refused"
+    And the error is returned by an HTTP handler
+    Then the response status is 500
+    And the problem member "detail" is "Internal Server Error"
+
+  Scenario: errors can be rendered differently
+    Given the error is "ErrNotFound"
+    And the server renders errors as plain text
+    When the error is returned by an HTTP handler
+    Then the response status is 404
+    And the response body is "NOT_FOUND"
+
+  Scenario: errors returned after the response was started are only logged
+    Given the error is "ErrUnavailable"
+    And the server logs errors
+    When the error is returned by an HTTP handler after it wrote the status 202
+    Then the server logged "UNAVAILABLE"
+    And the response status is 202
+    And the response body is ""
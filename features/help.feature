@@ -0,0 +1,34 @@
+Feature: Help links
+  Errors can point to documentation that explains them
+
+  Scenario: errors without help have no links
+    Given the error is "ErrNotFound"
+    Then the error has no help links
+
+  Scenario: help links can be attached to errors
+    Given the error is "ErrFailedPrecondition"
+    And the help link "https://example.com/docs/precondition" is attached
+    Then the help links include "https://example.com/docs/precondition"
+    And the error is a "ErrFailedPrecondition"
+
+  Scenario: help links can be registered for type codes
+    Given the help link "https://example.com/docs/teapot" is registered for "ErrImATeapot"
+    And the error is "ErrImATeapot"
+    When wrapped with the message "short and stout"
+    Then the help links include "https://example.com/docs/teapot"
+
+  Scenario: help links can be sent over GRPC
+    Given the error is "ErrOutOfRange"
+    And the help link "https://example.com/docs/range" is attached
+    When the error is sent over GRPC
+    Then the help links include "https://example.com/docs/range"
+    And the Type code is "OUT_OF_RANGE"
+
+  Scenario: errors with help links can be wrapped
+    Given the error is "ErrNotFound"
+    And the help link "https://example.com/docs/missing" is attached
+    When wrapped with the message "user 42"
+    Then the Type code is "NOT_FOUND"
+    And the error is a "ErrNotFound"
+    And the error message is "user 42"
+    And the help links include "https://example.com/docs/missing"
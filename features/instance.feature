@@ -0,0 +1,46 @@
+Feature: Error instance details
+  Errors can carry details about a single occurrence
+
+  Scenario: instance details are sent over GRPC
+    Given the error is "ErrUnavailable"
+    And the error has the ID "abc-123"
+    And the error has the public message "Please try again later"
+    And the error has the field "region" with the value "us-east-1"
+    And the error has the retry delay "5s"
+    When the error is sent over GRPC
+    Then the ID is "abc-123"
+    And the public message is "Please try again later"
+    And the field "region" is "us-east-1"
+    And the retry delay is "5s"
+    And the error is retryable
+    And the error has no timestamp
+
+  Scenario: timestamps are sent when asked for
+    Given the error is "ErrUnavailable"
+    When the error is sent over GRPC with a timestamp
+    Then the error has a timestamp
+
+  Scenario: errors can be marked as not retryable
+    Given the error is "ErrUnavailable"
+    And the error is marked as not retryable
+    When the error is sent over GRPC
+    Then the error is not retryable
+
+  Scenario: retryable errors by code
+    Given the error is "ErrInvalidArgument"
+    Then the error is not retryable
+
+  Scenario: version 1 error types can be received
+    Given a version 1 ErrorType for "ErrNotFound" was received
+    Then the Type code is "NOT_FOUND"
+    And the HTTP status is "Not Found"
+    And the GRPC code is "NotFound"
+    And the ID is ""
+    And the error is a "ErrNotFound"
+
+  Scenario: masked messages use the public message
+    Given an error with the message "connection refused"
+    And the error has the public message "Something went wrong"
+    And the server masks internal messages
+    When the error is returned by a unary GRPC handler
+    Then the error message is "Something went wrong"
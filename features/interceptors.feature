@@ -0,0 +1,76 @@
+Feature: GRPC interceptors
+  Interceptors convert errors for every RPC
+
+  Scenario: errors returned by unary handlers keep their codes
+    Given the error is "ErrNotFound"
+    When wrapped with the message "no such thing"
+    And the error is returned by a unary GRPC handler
+    Then the GRPC code is "NotFound"
+    And the HTTP status is "Not Found"
+    And the Type code is "NOT_FOUND"
+    And the error message is "no such thing"
+
+  Scenario: errors returned by stream handlers keep their codes
+    Given the error is "ErrUnprocessableEntity"
+    When wrapped with the message "bad stream"
+    And the error is returned by a streaming GRPC handler
+    Then the GRPC code is "InvalidArgument"
+    And the Type code is "UNPROCESSABLE_ENTITY"
+    And the error message is "bad stream"
+
+  Scenario: errors are logged by the server
+    Given the error is "ErrAborted"
+    And the server logs errors
+    When the error is returned by a unary GRPC handler
+    Then the server logged "ABORTED"
+
+  Scenario: messages of server faults can be masked
+    Given an error with the message "password=hunter2"
+    And the server masks internal messages
+    When the error is returned by a unary GRPC handler
+    Then the GRPC code is "Internal"
+    And the error message is "Internal Server Error"
+
+  Scenario: messages of statuses from other packages can be masked
+    Given a "codes.Internal" status error with the message "password=hunter2"
+    And the server masks internal messages
+    When the error is returned by a unary GRPC handler
+    Then the GRPC code is "Internal"
+    And the error message is "Internal Server Error"
+
+  Scenario: messages of wrapped statuses from other packages can be masked
+    Given a "codes.DataLoss" status error with the message "page 7 of /var/lib/db is corrupt"
+    When annotated with the message "read failed"
+    And the server masks internal messages
+    And the error is returned by a streaming GRPC handler
+    Then the GRPC code is "DataLoss"
+    And the error message is "Internal Server Error"
+
+  Scenario: statuses from other packages are sent unchanged without masking
+    Given a "codes.Internal" status error with the message "password=hunter2"
+    When the error is returned by a unary GRPC handler
+    Then the GRPC code is "Internal"
+    And the error message is "password=hunter2"
+
+  Scenario: messages of client errors are not masked
+    Given the error is "ErrInvalidArgument"
+    When wrapped with the message "name is required"
+    And the server masks internal messages
+    And the error is returned by a unary GRPC handler
+    Then the error message is "name is required"
+
+  Scenario: client interceptors receive errors from unary calls
+    Given the error is "ErrPermissionDenied"
+    And the client uses the error interceptors
+    When the error is returned by a unary GRPC handler
+    Then the error is a "ErrPermissionDenied"
+    And the Type code is "PERMISSION_DENIED"
+    And the HTTP status is "Forbidden"
+
+  Scenario: client interceptors receive errors returned mid-stream
+    Given the error is "ErrResourceExhausted"
+    And the client uses the error interceptors
+    When the error is returned by a streaming GRPC handler
+    Then the error is a "ErrResourceExhausted"
+    And the Type code is "RESOURCE_EXHAUSTED"
+    And the HTTP status is "Too Many Requests"
@@ -0,0 +1,140 @@
+Feature: JSON:API errors
+  Errors can be written as JSON:API errors documents and parsed back
+
+  Scenario: errors are error objects
+    Given the error is "ErrNotFound"
+    When wrapped with the message "user 42 not found"
+    And the error has the ID "req-42"
+    And the help link "https://example.com/errors/not-found" is attached
+    And the error has the field "parameter" with the value "id"
+    And the error has the public message "We could not find that user"
+    And the error is written as JSON:API errors
+    Then the response status is 404
+    And the response header "Content-Type" is "application/vnd.api+json"
+    And there is 1 JSON:API error
+    And JSON:API error 1 has the "status" "404"
+    And JSON:API error 1 has the "code" "NOT_FOUND"
+    And JSON:API error 1 has the "title" "Not Found"
+    And JSON:API error 1 has the "detail" "We could not find that user"
+    And JSON:API error 1 has the "id" "req-42"
+    And JSON:API error 1 has the "links.about" "https://example.com/errors/not-found"
+    And JSON:API error 1 has the "meta.parameter" "id"
+    And JSON:API error 1 has no "source"
+
+  Scenario: field violations are error objects with a source pointer
+    Given the error is "ErrUnprocessableEntity"
+    And the error has the field violation "email": "must be an email address"
+    And the error has the field violation "address.zip": "is required"
+    And the error has the field violation "/data/relationships/owner": "does not exist"
+    When the error is written as JSON:API errors
+    Then the response status is 422
+    And there are 3 JSON:API errors
+    And JSON:API error 1 has the "source.pointer" "/data/attributes/email"
+    And JSON:API error 1 has the "detail" "must be an email address"
+    And JSON:API error 1 has the "code" "UNPROCESSABLE_ENTITY"
+    And JSON:API error 2 has the "source.pointer" "/data/attributes/address/zip"
+    And JSON:API error 3 has the "source.pointer" "/data/relationships/owner"
+
+  Scenario: source pointers escape "~" and "/" in field names
+    Given the error is "ErrUnprocessableEntity"
+    And the error has the field violation "headers.content/type": "is not supported"
+    And the error has the field violation "tags.~draft": "is reserved"
+    When the error is written as JSON:API errors
+    Then JSON:API error 1 has the "source.pointer" "/data/attributes/headers/content~1type"
+    And JSON:API error 2 has the "source.pointer" "/data/attributes/tags/~0draft"
+
+  Scenario: escaped source pointers are parsed back
+    Given the error is "ErrUnprocessableEntity"
+    And the error has the field violation "headers.content/type": "is not supported"
+    And the error has the field violation "tags.~1draft": "is reserved"
+    When the error is written as JSON:API errors
+    And the JSON:API errors are parsed
+    Then field violation 1 is "headers.content/type": "is not supported"
+    And field violation 2 is "tags.~1draft": "is reserved"
+
+  Scenario: joined errors are error objects
+    Given the error is "ErrConflict"
+    And the error is joined with "ErrNotFound" with the message "team not found"
+    When the error is written as JSON:API errors
+    Then the response status is 400
+    And there are 2 JSON:API errors
+    And JSON:API error 1 has the "status" "409"
+    And JSON:API error 2 has the "status" "404"
+    And JSON:API error 2 has the "detail" "Not Found"
+
+  Scenario: joined errors with details are error objects
+    Given the error is "ErrConflict"
+    And the error is joined with "ErrNotFound" with the message "team not found"
+    And the error has the ID "req-42"
+    When the error is written as JSON:API errors
+    Then there are 2 JSON:API errors
+    And JSON:API error 1 has the "code" "CONFLICT"
+    And JSON:API error 2 has the "code" "NOT_FOUND"
+    And JSON:API error 2 has the "id" "req-42"
+
+  Scenario: server faults make the response a server fault
+    Given the error is "ErrBadRequest"
+    And the error is joined with "ErrInternal" with the message "pq: connection refused"
+    When the error is written as JSON:API errors
+    Then the response status is 500
+    And JSON:API error 2 has the "detail" "Internal Server Error"
+
+  Scenario: messages of errors are not written
+    Given the error is "ErrNotFound"
+    When wrapped with the message "internal row id 42"
+    And the error is written as JSON:API errors
+    Then the response status is 404
+    And JSON:API error 1 has the "detail" "Not Found"
+
+  Scenario: errors are parsed back
+    Given the error is "ErrNotFound"
+    When wrapped with the message "user 42 not found"
+    And the error has the public message "We could not find that user"
+    And the error has the ID "req-42"
+    And the help link "https://example.com/errors/not-found" is attached
+    And the error has the field "parameter" with the value "id"
+    And the error is written as JSON:API errors
+    And the JSON:API errors are parsed
+    Then the Type code is "NOT_FOUND"
+    And the HTTP status is "Not Found"
+    And the GRPC code is "NotFound"
+    And the error is a "ErrNotFound"
+    And the error message is "We could not find that user"
+    And the ID is "req-42"
+    And the field "parameter" is "id"
+    And the help links include "https://example.com/errors/not-found"
+
+  Scenario: field violations are parsed back into one error
+    Given the error is "ErrUnprocessableEntity"
+    And the error has the ID "req-42"
+    And the error has the field violation "email": "must be an email address"
+    And the error has the field violation "address.zip": "is required"
+    When the error is written as JSON:API errors
+    And the JSON:API errors are parsed
+    Then the Type code is "UNPROCESSABLE_ENTITY"
+    And the error has 2 field violations
+    And field violation 1 is "email": "must be an email address"
+    And field violation 2 is "address.zip": "is required"
+
+  Scenario: joined errors are parsed back
+    Given the error is "ErrConflict"
+    And the error is joined with "ErrNotFound" with the message "team not found"
+    When the error is written as JSON:API errors
+    And the JSON:API errors are parsed
+    Then the error is a "ErrConflict"
+    And the error is a "ErrNotFound"
+
+  Scenario: JSON:API responses are received
+    Given the error is "ErrUnprocessableEntity"
+    And the error has the field violation "email": "must be an email address"
+    When the error is written as JSON:API errors
+    And the response is received
+    Then the Type code is "UNPROCESSABLE_ENTITY"
+    And field violation 1 is "email": "must be an email address"
+
+  Scenario: field violations are kept in problems
+    Given the error is "ErrBadRequest"
+    And the error has the field violation "email": "must be an email address"
+    When the error is written as a problem
+    And the problem is parsed
+    Then field violation 1 is "email": "must be an email address"
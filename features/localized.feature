@@ -0,0 +1,23 @@
+Feature: Localized messages
+  Errors can carry user-facing messages for specific locales
+
+  Scenario: the technical message is unchanged
+    Given the error is "ErrNotFound"
+    And the localized message "Not here" is attached for "en"
+    Then the error message is "NOT_FOUND"
+    And the localized message is "Not here"
+
+  Scenario: the locale requested by the client is sent over GRPC
+    Given the error is "ErrNotFound"
+    And the localized message "Not here" is attached for "en"
+    And the localized message "Pas ici" is attached for "fr"
+    When the error is sent over GRPC to a client accepting "fr-CA,en;q=0.5"
+    Then the localized message is "Pas ici"
+    And the error message is "NOT_FOUND"
+
+  Scenario: the first message is sent when no locale matches
+    Given the error is "ErrNotFound"
+    And the localized message "Not here" is attached for "en"
+    And the localized message "Pas ici" is attached for "fr"
+    When the error is sent over GRPC to a client accepting "de"
+    Then the localized message is "Pas ici"
@@ -0,0 +1,49 @@
+Feature: Matching received errors
+  Errors received over GRPC are matched by their codes
+
+  Scenario Outline: received errors match equivalent errors by default
+    Given the error is "<error>"
+    When the error is sent over GRPC
+    Then the error is a "<error>"
+    And the error is a "<equivalent>"
+
+    Examples:
+      | error             | equivalent         |
+      | ErrAlreadyExists  | ErrConflict        |
+      | ErrAlreadyExists  | ErrAborted         |
+      | ErrBadRequest     | ErrInvalidArgument |
+
+  Scenario Outline: received errors can be matched strictly
+    Given strict matching is enabled
+    And the error is "<error>"
+    When the error is sent over GRPC
+    Then the error is a "<error>"
+    And the error is not a "<equivalent>"
+
+    Examples:
+      | error             | equivalent         |
+      | ErrAlreadyExists  | ErrConflict        |
+      | ErrAlreadyExists  | ErrAborted         |
+      | ErrBadRequest     | ErrInvalidArgument |
+
+  Scenario: strict matching can be chosen for a single call
+    Given the error is "ErrAlreadyExists"
+    When the error is sent over GRPC and received with strict matching
+    Then the error is a "ErrAlreadyExists"
+    And the error is not a "ErrConflict"
+
+  Scenario: strict matching applies to every received layer
+    Given the error is "ErrBadRequest"
+    And strict matching is enabled
+    When wrapped with the error "ErrNotFound" and message "user not found"
+    And the error is sent over GRPC with the cause chain
+    Then the error is a "ErrBadRequest"
+    And the error is not a "ErrInvalidArgument"
+
+  Scenario: client interceptors can match strictly
+    Given the error is "ErrAlreadyExists"
+    And the client uses the error interceptors
+    And the client matches strictly
+    When the error is returned by a unary GRPC handler
+    Then the error is a "ErrAlreadyExists"
+    And the error is not a "ErrConflict"
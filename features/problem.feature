@@ -0,0 +1,101 @@
+Feature: Problem details
+  Errors can be written as RFC 9457 application/problem+json responses and parsed back
+
+  Scenario: problems have the codes of the error
+    Given the error is "ErrNotFound"
+    When wrapped with the message "user 42 not found"
+    And the error is written as a problem
+    Then the response status is 404
+    And the response header "Content-Type" is "application/problem+json"
+    And the response header "X-Type-Code" is "NOT_FOUND"
+    And the problem member "status" is "404"
+    And the problem member "code" is "NOT_FOUND"
+    And the problem member "type" is "about:blank"
+    And the problem member "title" is "Not Found"
+    And the problem member "detail" is "Not Found"
+    And the problem has no member "instance"
+
+  Scenario: problems use the help link, public message and ID
+    Given the error is "ErrNotFound"
+    And the help link "https://example.com/errors/not-found" is attached
+    And the error has the public message "We could not find that user"
+    And the error has the ID "req-42"
+    When the error is written as a problem
+    Then the problem member "type" is "https://example.com/errors/not-found"
+    And the problem member "detail" is "We could not find that user"
+    And the problem member "instance" is "req-42"
+
+  Scenario: fields do not replace the type code
+    Given the error is "ErrBadRequest"
+    And the error has the field "code" with the value "E42"
+    When the error is written as a problem
+    Then the problem member "code" is "BAD_REQUEST"
+
+  Scenario: parsed problems do not have a public message
+    Given the error is "ErrNotFound"
+    And the error has the public message "We could not find that user"
+    When the error is written as a problem
+    And the problem is parsed
+    Then the error message is "We could not find that user"
+    And the public message is ""
+
+  Scenario: fields are extension members
+    Given the error is "ErrBadRequest"
+    And the error has the field "parameter" with the value "email"
+    And the error has the retry delay "2s"
+    When the error is written as a problem
+    Then the problem member "parameter" is "email"
+    And the problem member "retryDelay" is "2s"
+
+  Scenario: fields do not replace the defined members
+    Given the error is "ErrBadRequest"
+    And the error has the field "status" with the value "ok"
+    When the error is written as a problem
+    Then the problem member "status" is "400"
+
+  Scenario: details use the locales requested by the client
+    Given the error is "ErrNotFound"
+    And the localized message "Not here" is attached for "en"
+    And the localized message "Pas ici" is attached for "fr"
+    When the error is written as a problem to a client accepting "fr-CA, en;q=0.5"
+    Then the problem member "detail" is "Pas ici"
+
+  Scenario: messages of server faults are not written
+    Given the error is "ErrInternal"
+    When wrapped with the message "pq: connection refused"
+    And the error is written as a problem
+    Then the response status is 500
+    And the problem member "detail" is "Internal Server Error"
+
+  Scenario: problems are parsed back into errors
+    Given the error is "ErrAlreadyExists"
+    And the help link "https://example.com/errors/exists" is attached
+    And the error has the ID "req-42"
+    And the error has the field "parameter" with the value "email"
+    And the error has the retry delay "2s"
+    When the error is written as a problem
+    And the problem is parsed
+    Then the Type code is "ALREADY_EXISTS"
+    And the HTTP status is "Conflict"
+    And the GRPC code is "AlreadyExists"
+    And the error is a "ErrAlreadyExists"
+    And the ID is "req-42"
+    And the field "parameter" is "email"
+    And the retry delay is "2s"
+    And the help links include "https://example.com/errors/exists"
+
+  Scenario: problems with custom type codes use the HTTP status
+    Given an error with Type code "OUT_OF_STOCK"
+    And an error with HTTP status "http.StatusConflict"
+    When the error is written as a problem
+    And the problem is parsed
+    Then the Type code is "OUT_OF_STOCK"
+    And the GRPC code is "AlreadyExists"
+    And the HTTP status is "Conflict"
+
+  Scenario: registered details are parsed back into errors
+    Given the "user" resource "42" is wrapped with the error "ErrNotFound"
+    When the error is written as a problem
+    And the problem is parsed
+    Then the error is the "user" resource "42"
+    And the error is a "ErrNotFound"
@@ -0,0 +1,82 @@
+Feature: Rate limit headers
+  Retry-After and RateLimit headers are written from the hints attached to errors
+
+  Scenario Outline: retry delays are written as Retry-After
+    Given the error is "<error>"
+    And the error has the retry delay "1500ms"
+    When the error is written as a problem
+    Then the response status is <status>
+    And the response header "Retry-After" is "2"
+
+    Examples:
+      | error                 | status |
+      | ErrTooManyRequests    | 429    |
+      | ErrResourceExhausted  | 429    |
+      | ErrServiceUnavailable | 503    |
+      | ErrUnavailable        | 503    |
+
+  Scenario: quotas are written as RateLimit headers
+    Given the error is "ErrTooManyRequests"
+    And the error has the rate limit 100 with 0 remaining resetting in "30s"
+    When the error is written as a problem
+    Then the response header "RateLimit-Limit" is "100"
+    And the response header "RateLimit-Remaining" is "0"
+    And the response header "RateLimit-Reset" is "30"
+    And the response header "Retry-After" is "30"
+
+  Scenario: retry delays take precedence over the quota reset
+    Given the error is "ErrTooManyRequests"
+    And the error has the rate limit 100 with 0 remaining resetting in "30s"
+    And the error has the retry delay "5s"
+    When the error is written as a problem
+    Then the response header "Retry-After" is "5"
+    And the response header "RateLimit-Reset" is "30"
+
+  Scenario: quotas that are not exhausted do not ask the client to wait
+    Given the error is "ErrServiceUnavailable"
+    And the error has the rate limit 100 with 40 remaining resetting in "30s"
+    When the error is written as a problem
+    Then the response header "RateLimit-Remaining" is "40"
+    And the response has no header "Retry-After"
+
+  Scenario: other errors do not get the headers
+    Given the error is "ErrBadRequest"
+    And the error has the retry delay "5s"
+    And the error has the rate limit 100 with 0 remaining resetting in "30s"
+    When the error is written as a problem
+    Then the response has no header "Retry-After"
+    And the response has no header "RateLimit-Limit"
+
+  Scenario: custom renderers get the headers too
+    Given the error is "ErrTooManyRequests"
+    And the error has the retry delay "3s"
+    And the server renders errors as plain text
+    When the error is returned by an HTTP handler
+    Then the response header "Retry-After" is "3"
+
+  Scenario: the headers are received with the error
+    Given the error is "ErrTooManyRequests"
+    And the error has the rate limit 100 with 0 remaining resetting in "30s"
+    When the error is written as a problem
+    And the response is received
+    Then the rate limit is 100 with 0 remaining resetting in "30s"
+    And the retry delay is "30s"
+    And the error is retryable
+
+  Scenario: Retry-After is received from other servers
+    Given the server responds with the status 503 and the header "Retry-After" of "120"
+    When the response is received
+    Then the retry delay is "2m0s"
+    And the Type code is "SERVICE_UNAVAILABLE"
+
+  Scenario Outline: incomplete RateLimit headers are not a quota
+    Given the server responds with the status 429 and the header "<header>" of "<value>"
+    When the response is received
+    Then the error has no rate limit
+    And the Type code is "TOO_MANY_REQUESTS"
+
+    Examples:
+      | header              | value |
+      | RateLimit-Limit     | 100   |
+      | RateLimit-Remaining | 0     |
+      | RateLimit-Reset     | 30    |
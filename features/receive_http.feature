@@ -0,0 +1,84 @@
+Feature: Receiving HTTP errors
+  Errors are recreated from the responses of HTTP servers
+
+  Scenario: problems keep the codes of the error
+    Given the error is "ErrNotFound"
+    When wrapped with the message "user 42 not found"
+    And the error has the public message "We could not find that user"
+    And the error has the ID "req-42"
+    And the error is written as a problem
+    And the response is received
+    Then the Type code is "NOT_FOUND"
+    And the HTTP status is "Not Found"
+    And the GRPC code is "NotFound"
+    And the error is a "ErrNotFound"
+    And the ID is "req-42"
+    And the error message is "We could not find that user"
+
+  Scenario: problems keep custom type codes
+    Given an error with Type code "OUT_OF_STOCK"
+    And an error with HTTP status "http.StatusConflict"
+    When the error is written as a problem
+    And the response is received
+    Then the Type code is "OUT_OF_STOCK"
+    And the HTTP status is "Conflict"
+    And the GRPC code is "AlreadyExists"
+
+  Scenario Outline: bare statuses use the Error for the status
+    Given the server responds with the status <status>
+    When the response is received
+    Then the Type code is "<type>"
+    And the GRPC code is "<grpc>"
+    And the error is a "<error>"
+
+    Examples:
+      | status | type                | grpc               | error                 |
+      | 400    | BAD_REQUEST         | InvalidArgument    | ErrBadRequest         |
+      | 404    | NOT_FOUND           | NotFound           | ErrNotFound           |
+      | 429    | TOO_MANY_REQUESTS   | ResourceExhausted  | ErrTooManyRequests    |
+      | 503    | SERVICE_UNAVAILABLE | Unavailable        | ErrServiceUnavailable |
+      | 420    | BAD_REQUEST         | InvalidArgument    | ErrBadRequest         |
+      | 599    | INTERNAL_SERVER_ERROR | Internal         | ErrInternalServerError |
+
+  Scenario: problem statuses that disagree with the response are ignored
+    Given the server responds with the status 500 and the problem:
+      """
+      {"status": 200, "code": "NOT_FOUND"}
+      """
+    When the response is received
+    Then the HTTP status is "Internal Server Error"
+    And the GRPC code is "Internal"
+    And the Type code is "NOT_FOUND"
+
+  Scenario: the type code header is used without a problem
+    Given the server responds with the status 409 and the type code "ABORTED"
+    When the response is received
+    Then the Type code is "ABORTED"
+    And the GRPC code is "Aborted"
+    And the error is a "ErrAborted"
+
+  Scenario: other bodies are left alone
+    Given the server responds with the status 502 and the body "upstream failed"
+    When the response is received
+    Then the Type code is "BAD_GATEWAY"
+    And the error message is "Bad Gateway"
+    And the received body is "upstream failed"
+
+  Scenario: problem bodies are still readable
+    Given the error is "ErrNotFound"
+    When the error is written as a problem
+    And the response is received
+    Then the received body contains "NOT_FOUND"
+
+  Scenario: successful responses are not errors
+    Given the server responds with the status 200
+    When the response is received
+    Then the call succeeded
+
+  Scenario: received errors can be matched strictly
+    Given the error is "ErrAlreadyExists"
+    And the client matches strictly
+    When the error is written as a problem
+    And the response is received
+    Then the error is a "ErrAlreadyExists"
+    And the error is not a "ErrConflict"
@@ -0,0 +1,62 @@
+Feature: Recovering panics in HTTP handlers
+  Panics are written as ErrInternalServerError responses
+
+  Scenario: panics are written as problems
+    Given the server logs errors
+    When a handler panics with "nil map"
+    Then the response status is 500
+    And the response header "Content-Type" is "application/problem+json"
+    And the problem member "code" is "INTERNAL_SERVER_ERROR"
+    And the problem member "detail" is "Internal Server Error"
+    And the server logged "INTERNAL_SERVER_ERROR"
+
+  Scenario: the value of the panic is only kept in the cause
+    Given the server logs errors
+    When a handler panics with "password=hunter2"
+    Then the problem member "detail" is "Internal Server Error"
+    And the logged error message is "Internal Server Error"
+    And the logged error has the public message "Internal Server Error"
+    And the logged error has the cause "panic: password=hunter2"
+
+  Scenario: panics are not written with their value
+    When a handler panics with "nil map"
+    Then the response status is 500
+    And the problem member "detail" is "Internal Server Error"
+
+  Scenario: panics with errors keep the error
+    Given the error is "ErrNotFound"
+    And the server logs errors
+    When a handler panics with the error
+    Then the response status is 500
+    And the logged error is a "ErrInternalServerError"
+    And the logged error is a "ErrNotFound"
+
+  Scenario: the stack of the panic is kept
+    Given the server logs errors
+    When a handler panics with "nil map"
+    Then the logged error has the stack of the panic
+
+  Scenario: panics after the headers were written abort the response
+    Given the server logs errors
+    When a handler panics after it wrote the status 202
+    Then the response was aborted
+    And the response body is ""
+    And the server logged "INTERNAL_SERVER_ERROR"
+
+  Scenario: panics after the connection was hijacked abort the response
+    Given the server logs errors
+    When a handler panics after it hijacked the connection
+    Then the response was aborted
+    And the response body is ""
+    And the server logged "INTERNAL_SERVER_ERROR"
+
+  Scenario: aborted responses are not recovered
+    Given the server logs errors
+    When a handler aborts the response
+    Then the panic was not recovered
+
+  Scenario: panics use the renderer
+    Given the server renders errors as plain text
+    When a handler panics with "nil map"
+    Then the response status is 500
+    And the response body is "INTERNAL_SERVER_ERROR"
@@ -0,0 +1,75 @@
+Feature: Negotiated error responses
+  Errors are written in the media type that best matches the Accept header
+
+  Scenario Outline: the media type follows the Accept header
+    Given the error is "ErrNotFound"
+    When the error is rendered for a client accepting "<accept>"
+    Then the response status is 404
+    And the response header "Content-Type" is "<content type>"
+    And the response header "Vary" is "Accept"
+    And the response body contains "<body>"
+
+    Examples:
+      | accept                                                            | content type                | body                               |
+      |                                                                   | application/problem+json    | "code":"NOT_FOUND"                 |
+      | */*                                                               | application/problem+json    | "code":"NOT_FOUND"                 |
+      | application/json                                                  | application/json            | "code":"NOT_FOUND"                 |
+      | application/problem+xml                                           | application/problem+xml     | <title>Not Found</title>           |
+      | application/xml                                                   | application/xml             | <problem xmlns="urn:ietf:rfc:7807"> |
+      | text/plain                                                        | text/plain; charset=utf-8   | NOT_FOUND: Not Found               |
+      | text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8   | text/html; charset=utf-8    | <h1>404 Not Found</h1>             |
+      | text/*;q=0.5, application/json;q=0.4                              | text/html; charset=utf-8    | <title>404 Not Found</title>       |
+      | image/png                                                         | application/problem+json    | "status":404                       |
+      | application/json;q=0, */*;q=0.1                                   | application/problem+json    | "status":404                       |
+
+  Scenario: XML documents include the extension members
+    Given the error is "ErrUnprocessableEntity"
+    And the error has the field violation "email": "must be an email address"
+    When the error is rendered for a client accepting "application/problem+xml"
+    Then the response body contains "<code>UNPROCESSABLE_ENTITY</code>"
+    And the response body contains "<invalid-params><i><name>email</name><reason>must be an email address</reason></i></invalid-params>"
+
+  Scenario: HTML pages escape the error
+    Given the error is "ErrBadRequest"
+    And the error has the public message "<script>alert(1)</script>"
+    When the error is rendered for a client accepting "text/html"
+    Then the response body contains "&lt;script&gt;alert(1)&lt;/script&gt;"
+
+  Scenario: HTML pages link to the help
+    Given the error is "ErrNotFound"
+    And the help link "https://example.com/errors/not-found" is attached
+    And the error has the ID "req-42"
+    When the error is rendered for a client accepting "text/html"
+    Then the response body contains "<a href="https://example.com/errors/not-found">"
+    And the response body contains "<code>req-42</code>"
+
+  Scenario: HTML templates can be replaced
+    Given the error is "ErrNotFound"
+    And the renderer uses the HTML template "<p>Sorry, {{.TypeCode}}</p>"
+    When the error is rendered for a client accepting "text/html"
+    Then the response body is "<p>Sorry, NOT_FOUND</p>"
+
+  Scenario: media types can be added
+    Given the error is "ErrNotFound"
+    And the renderer writes "application/yaml" as "code: %s"
+    When the error is rendered for a client accepting "application/yaml"
+    Then the response header "Content-Type" is "application/yaml"
+    And the response body is "code: NOT_FOUND"
+
+  Scenario: added media types replace the built in types
+    Given the error is "ErrNotFound"
+    And the renderer writes "application/json" as "{"code":"%s"}"
+    When the error is rendered for a client accepting "application/json"
+    Then the response body is "{"code":"NOT_FOUND"}"
+
+  Scenario: messages of server faults are not rendered
+    Given the error is "ErrInternal"
+    When wrapped with the message "pq: connection refused"
+    And the error is rendered for a client accepting "text/plain"
+    Then the response body is "INTERNAL: Internal Server Error"
+
+  Scenario: rendered errors get the retry headers
+    Given the error is "ErrTooManyRequests"
+    And the error has the retry delay "3s"
+    When the error is rendered for a client accepting "text/html"
+    Then the response header "Retry-After" is "3"
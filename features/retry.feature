@@ -0,0 +1,91 @@
+Feature: Retrying calls
+  Clients can retry calls that failed with retryable errors
+
+  Scenario: retryable errors are retried
+    Given the error is "ErrUnavailable"
+    And the client retries up to 3 times
+    And the handler succeeds after 2 failures
+    When the error is returned by a unary GRPC handler
+    Then the call succeeded
+    And the handler was called 3 times
+
+  Scenario Outline: errors with retryable type codes are retried
+    Given the error is "<error>"
+    And the client retries up to 2 times
+    And the handler succeeds after 1 failure
+    When the error is returned by a unary GRPC handler
+    Then the call succeeded
+    And the handler was called 2 times
+
+    Examples:
+      | error                 |
+      | ErrAborted            |
+      | ErrResourceExhausted  |
+      | ErrServiceUnavailable |
+      | ErrTooManyRequests    |
+
+  Scenario: retries stop after the last attempt
+    Given the error is "ErrServiceUnavailable"
+    And the client retries up to 3 times
+    When the error is returned by a unary GRPC handler
+    Then the Type code is "SERVICE_UNAVAILABLE"
+    And the handler was called 3 times
+
+  Scenario Outline: errors that are not retryable are returned at once
+    Given the error is "<error>"
+    And the client retries up to 3 times
+    And the handler succeeds after 1 failure
+    When the error is returned by a unary GRPC handler
+    Then the Type code is "<type code>"
+    And the handler was called 1 time
+
+    Examples:
+      | error                         | type code                     |
+      | ErrInvalidArgument            | INVALID_ARGUMENT              |
+      | ErrNotFound                   | NOT_FOUND                     |
+      | ErrUnavailableForLegalReasons | UNAVAILABLE_FOR_LEGAL_REASONS |
+      | ErrInsufficientStorage        | INSUFFICIENT_STORAGE          |
+      | ErrBadGateway                 | BAD_GATEWAY                   |
+      | ErrMisdirectedRequest         | MISDIRECTED_REQUEST           |
+      | ErrTooEarly                   | TOO_EARLY                     |
+
+  Scenario: retry hints sent by the server are honored
+    Given the error is "ErrNotFound"
+    And the error has the retry delay "50ms"
+    And the client retries up to 2 times
+    And the handler succeeds after 1 failure
+    When the error is returned by a unary GRPC handler
+    Then the call succeeded
+    And the handler was called 2 times
+    And the call took at least "50ms"
+
+  Scenario: servers can say an error is not retryable
+    Given the error is "ErrUnavailable"
+    And the error is marked as not retryable
+    And the client retries up to 3 times
+    When the error is returned by a unary GRPC handler
+    Then the Type code is "UNAVAILABLE"
+    And the handler was called 1 time
+
+  Scenario: retries stop at the deadline
+    Given the error is "ErrUnavailable"
+    And the error has the retry delay "5s"
+    And the client retries up to 3 times
+    And the call times out after "1s"
+    When the error is returned by a unary GRPC handler
+    Then the Type code is "UNAVAILABLE"
+    And the handler was called 1 time
+    And the call took less than "1s"
+
+  Scenario: errors returned mid-stream are not retried
+    Given the error is "ErrUnavailable"
+    And the client retries up to 3 times
+    When the error is returned by a streaming GRPC handler
+    Then the Type code is "UNAVAILABLE"
+    And the handler was called 1 time
+
+  Scenario: retry delays are read from RetryInfo details
+    Given a "codes.Unavailable" status was sent with a RetryInfo of "2s"
+    When the error is received from GRPC
+    Then the retry delay is "2s"
+    And the error is retryable
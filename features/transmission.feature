@@ -36,3 +36,42 @@ Feature: Transmission of errors over GRPC
     Then the error is a "ErrNotImplemented"
     Then the error is a "ErrPermissionDenied"
     Then the error is a "ErrBadRequest"
+
+  Scenario: received errors that are not statuses are kept
+    Given the error is the standard error "io.EOF"
+    When the error is received from GRPC
+    Then the GRPC code is "Unknown"
+    And the Type code is "UNKNOWN"
+    And the error message is "EOF"
+    And the error wraps "io.EOF"
+
+  Scenario Outline: received context errors are classified
+    Given the error is the standard error "<error>"
+    When the error is received from GRPC
+    Then the GRPC code is "<grpc code>"
+    And the Type code is "<type code>"
+    And the error is a "<coded error>"
+    And the error wraps "<error>"
+
+    Examples:
+      | error                    | grpc code        | type code         | coded error         |
+      | context.Canceled         | Canceled         | CANCELED          | ErrCanceled         |
+      | context.DeadlineExceeded | DeadlineExceeded | DEADLINE_EXCEEDED | ErrDeadlineExceeded |
+
+  Scenario: relayed errors keep the details of the upstream service
+    Given the error is "ErrNotFound"
+    And an upstream service "users" sent the error with an ErrorInfo and a QuotaFailure
+    When the error is relayed over GRPC by "gateway"
+    Then the status has an ErrorInfo with the reason "NOT_FOUND"
+    And the status has a QuotaFailure
+    And the status code is "NotFound"
+    And the Type code is "NOT_FOUND"
+    And the origin is "users"
+
+  Scenario: errors with attached details are sent with the options
+    Given the error is the standard error "io.EOF"
+    And the error has the ID "req-1"
+    When the error is sent over GRPC from "users" with the default type "ErrInternal"
+    Then the GRPC code is "Internal"
+    And the origin is "users"
+    And the ID is "req-1"
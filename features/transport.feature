@@ -0,0 +1,73 @@
+Feature: HTTP transport
+  Requests sent with Do through the Transport return coded errors for error statuses and when they get no response
+
+  Scenario: error responses are returned by the client
+    Given the server responds with the status 404
+    When the request is sent through the transport
+    Then the received status is 404
+    And the error is a "ErrNotFound"
+
+  Scenario: problems in responses are received as coded errors
+    Given the error is "ErrNotFound"
+    And the error has the ID "req-42"
+    When the error is written as a problem
+    And the request is sent through the transport
+    Then the Type code is "NOT_FOUND"
+    And the HTTP status is "Not Found"
+    And the GRPC code is "NotFound"
+    And the error is a "ErrNotFound"
+    And the ID is "req-42"
+
+  Scenario: statuses without a body use the Error for the status
+    Given the server responds with the status 503
+    When the request is sent through the transport
+    Then the Type code is "SERVICE_UNAVAILABLE"
+    And the GRPC code is "Unavailable"
+    And the error is retryable
+
+  Scenario: the response body is still readable
+    Given the server responds with the status 502 and the body "upstream failed"
+    When the request is sent through the transport
+    Then the Type code is "BAD_GATEWAY"
+    And the received status is 502
+    And the received body is "upstream failed"
+
+  Scenario: problem bodies are still readable
+    Given the error is "ErrConflict"
+    When the error is written as a problem
+    And the request is sent through the transport
+    Then the Type code is "CONFLICT"
+    And the received body contains ""code":"CONFLICT""
+
+  Scenario: successful responses are returned unchanged
+    Given the server responds with the status 200
+    When the request is sent through the transport
+    Then the call succeeded
+
+  Scenario: servers that cannot be reached are unavailable
+    When the request is sent through the transport to a closed server
+    Then the Type code is "UNAVAILABLE"
+    And the GRPC code is "Unavailable"
+    And the error is a "ErrUnavailable"
+
+  Scenario: requests that time out have exceeded their deadline
+    When the request through the transport times out
+    Then the Type code is "DEADLINE_EXCEEDED"
+    And the GRPC code is "DeadlineExceeded"
+    And the error is a "ErrDeadlineExceeded"
+
+  Scenario: transport errors keep their codes when sent over GRPC
+    Given the server responds with the status 409 and the type code "ABORTED"
+    When the request is sent through the transport
+    And the error is sent over GRPC
+    Then the Type code is "ABORTED"
+    And the GRPC code is "Aborted"
+
+  Scenario: details can be ignored for a host
+    Given an error with Type code "CUSTOM"
+    And an error with HTTP status "http.StatusConflict"
+    And details are not accepted from any server
+    When the error is written as a problem
+    And the request is sent through the transport
+    Then the Type code is "CONFLICT"
+    And the error is a "ErrConflict"
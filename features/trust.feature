@@ -0,0 +1,84 @@
+Feature: Trusting received errors
+  Clients decide how much of the details sent by servers they believe
+
+  Scenario: received details are trusted by default
+    Given a "codes.NotFound" status was sent with an ErrorType claiming HTTP 200 and GRPC "codes.Internal"
+    When the error is received from GRPC
+    Then the Type code is "FORGED"
+    And the HTTP code is 200
+    And the GRPC code is "Internal"
+    And no mismatch was reported
+
+  Scenario: HTTP codes that are not errors can be rejected
+    Given a "codes.NotFound" status was sent with an ErrorType claiming HTTP 200 and GRPC "codes.NotFound"
+    And the client rejects HTTP codes that are not errors
+    When the error is received from GRPC
+    Then the Type code is "FORGED"
+    And the HTTP status is "Not Found"
+    And a "http code out of range" mismatch was reported
+
+  Scenario: rejected HTTP codes are replaced using the status code
+    Given a "codes.NotFound" status was sent with an ErrorType claiming HTTP 200 and GRPC "codes.Internal"
+    And the client rejects HTTP codes that are not errors
+    When the error is received from GRPC
+    Then the HTTP status is "Not Found"
+    And a "http code out of range" mismatch was reported
+
+  Scenario Outline: HTTP codes that are not errors can be clamped
+    Given a "codes.NotFound" status was sent with an ErrorType claiming HTTP <claimed> and GRPC "codes.NotFound"
+    And the client clamps HTTP codes that are not errors
+    When the error is received from GRPC
+    Then the HTTP code is <clamped>
+    And a "http code out of range" mismatch was reported
+
+    Examples:
+      | claimed | clamped |
+      | 200     | 400     |
+      | 302     | 400     |
+      | 999     | 599     |
+
+  Scenario: HTTP codes that are errors are kept
+    Given a "codes.NotFound" status was sent with an ErrorType claiming HTTP 410 and GRPC "codes.NotFound"
+    And the client rejects HTTP codes that are not errors
+    When the error is received from GRPC
+    Then the HTTP code is 410
+    And no mismatch was reported
+
+  Scenario: details claiming a different GRPC code can be ignored
+    Given a "codes.NotFound" status was sent with an ErrorType claiming HTTP 500 and GRPC "codes.Internal"
+    And the client verifies GRPC codes
+    When the error is received from GRPC
+    Then the GRPC code is "NotFound"
+    And the Type code is "NOT_FOUND"
+    And the HTTP status is "Not Found"
+    And the error message is "forged error"
+    And a "grpc code differs from status" mismatch was reported
+
+  Scenario: statuses without details take the HTTP code of their GRPC code
+    Given a "codes.Unavailable" status was sent without details
+    When the error is received from GRPC
+    Then the Type code is "UNAVAILABLE"
+    And the HTTP status is "Service Unavailable"
+    And the GRPC code is "Unavailable"
+
+  Scenario: details can be ignored for a service
+    Given an error with Type code "CUSTOM"
+    And the client uses the error interceptors
+    And the client does not accept details from "grpc.health.v1.Health"
+    When the error is returned by a unary GRPC handler
+    Then the Type code is "UNKNOWN"
+    And the GRPC code is "Unknown"
+
+  Scenario: details can be ignored by default
+    Given an error with Type code "CUSTOM"
+    And details are not accepted from any server
+    When the error is sent over GRPC
+    Then the Type code is "UNKNOWN"
+
+  Scenario: mismatches identify the call
+    Given a "codes.NotFound" status was sent with an ErrorType claiming HTTP 500 and GRPC "codes.Internal"
+    And the client uses the error interceptors
+    And the client verifies GRPC codes
+    When the error is returned by a unary GRPC handler
+    Then the GRPC code is "NotFound"
+    And a "grpc code differs from status" mismatch was reported for "/grpc.health.v1.Health/Check"
@@ -28,3 +28,19 @@ Feature: Wrapping errors
     And the error message is "some error"
     And the error is a "ErrBadRequest"
     And the error is a "ErrForbidden"
+
+  Scenario: attached details do not change the codes of wrapped errors
+    Given the error is the standard error "io.EOF"
+    And the error has the ID "req-1"
+    When wrapped with the message "read failed"
+    Then the Type code is "INTERNAL_SERVER_ERROR"
+    And the HTTP status is "Internal Server Error"
+    And the ID is "req-1"
+
+  Scenario: attached details do not change the message of wrapped errors
+    Given the error is "ErrNotFound"
+    And the error has the ID "req-1"
+    When wrapped with the message "user 42"
+    Then the error message is "user 42"
+    And the Type code is "NOT_FOUND"
+    And the ID is "req-1"
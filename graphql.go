@@ -0,0 +1,193 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// extension members written by NewGraphQLError()
+const (
+	graphqlCode            = "code"
+	graphqlHTTPCode        = "httpCode"
+	graphqlGRPCCode        = "grpcCode"
+	graphqlID              = "id"
+	graphqlFields          = "fields"
+	graphqlFieldViolations = "fieldViolations"
+)
+
+// GraphQLError is a GraphQL error object
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLLocation      `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLLocation is a position in a GraphQL document
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLErrors are the errors of a GraphQL response
+//
+// Use it as the type of the "errors" member when decoding responses.
+type GraphQLErrors []GraphQLError
+
+// NewGraphQLError returns the GraphQL error object for the error
+//
+// The message is the public message; otherwise the localized message that
+// best matches the locales given with WithLocale(), or the status text. The
+// message of the error is never used as it may reveal server internals. The
+// extensions are:
+//
+//   - code is the type code of the error
+//   - httpCode and grpcCode are the HTTP status and GRPC code of the error
+//   - id is the ID of the error
+//   - fields are the fields attached to the error
+//   - fieldViolations are the field violations of the error, each with a field and description
+//
+// Set Path and Locations to where the error happened in the query.
+// If err is nil then NewGraphQLError returns nil.
+func NewGraphQLError(err error, opts ...Option) *GraphQLError {
+	if err == nil {
+		return nil
+	}
+
+	o := newOptions(opts)
+
+	grpcCode, httpCode, typeCode := o.codes(err)
+
+	extensions := map[string]interface{}{
+		graphqlCode:     typeCode,
+		graphqlHTTPCode: httpCode,
+		graphqlGRPCCode: int(grpcCode),
+	}
+	if id := ID(err); id != "" {
+		extensions[graphqlID] = id
+	}
+	if fields := Fields(err); len(fields) != 0 {
+		extensions[graphqlFields] = fields
+	}
+	if violations := FieldViolations(err); len(violations) != 0 {
+		items := make([]map[string]string, len(violations))
+		for i, v := range violations {
+			items[i] = map[string]string{"field": v.Field, "description": v.Description}
+		}
+		extensions[graphqlFieldViolations] = items
+	}
+
+	return &GraphQLError{
+		Message:    o.publicDetail(err, httpCode),
+		Extensions: extensions,
+	}
+}
+
+// NewGraphQLErrors returns a GraphQL error object from NewGraphQLError() for each of the joined errors
+//
+// Details attached to the joined errors as a whole, such as an ID, are given to each object.
+// If err is nil then NewGraphQLErrors returns nil.
+func NewGraphQLErrors(err error, opts ...Option) GraphQLErrors {
+	if err == nil {
+		return nil
+	}
+	var errs GraphQLErrors
+	for _, err := range joinedErrors(err) {
+		errs = append(errs, *NewGraphQLError(err, opts...))
+	}
+	return errs
+}
+
+// ParseGraphQLErrors reads the errors of a GraphQL response
+func ParseGraphQLErrors(r io.Reader) (GraphQLErrors, error) {
+	var response struct {
+		Errors GraphQLErrors `json:"errors"`
+	}
+	if err := json.NewDecoder(r).Decode(&response); err != nil {
+		return nil, err
+	}
+	return response.Errors, nil
+}
+
+// Err converts the errors back into coded errors
+//
+// More than one error is returned as joined errors.
+// If there are no errors then Err returns nil.
+func (errs GraphQLErrors) Err() error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0].Err()
+	}
+	joined := make([]error, len(errs))
+	for i, e := range errs {
+		joined[i] = e.Err()
+	}
+	return stderrors.Join(joined...)
+}
+
+// Err converts the error object back into a coded error
+//
+// The code extension is used as the type code. Without an httpCode extension
+// the HTTP status is that of the Error for the code, with the common codes
+// "BAD_USER_INPUT", "GRAPHQL_PARSE_FAILED" and "GRAPHQL_VALIDATION_FAILED"
+// taken to be bad requests. The result supports the same functions as errors
+// received over GRPC, including errors.Is(), ID(), Fields() and
+// FieldViolations().
+func (e GraphQLError) Err() error {
+	o := newOptions(nil)
+
+	typeCode, _ := e.Extensions[graphqlCode].(string)
+	httpCode := 0
+	if code, ok := e.Extensions[graphqlHTTPCode].(float64); ok {
+		httpCode = int(code)
+	}
+	if httpCode == 0 {
+		httpCode = graphqlHTTPStatus(typeCode)
+	}
+
+	ge := httpError(typeCode, httpCode, e.Message, o)
+	if code, ok := e.Extensions[graphqlGRPCCode].(float64); ok && code > 0 {
+		ge.gc = codes.Code(code)
+		ge.s = status.New(ge.gc, ge.m)
+	}
+
+	if e.Message != "" {
+		ge.details = append(ge.details, publicMessage(e.Message))
+	}
+	if id, ok := e.Extensions[graphqlID].(string); ok && id != "" {
+		ge.details = append(ge.details, errorID(id))
+	}
+	fields, _ := e.Extensions[graphqlFields].(map[string]interface{})
+	for key, value := range fields {
+		if s, ok := value.(string); ok {
+			ge.details = append(ge.details, field{key: key, value: s})
+		}
+	}
+	violations, _ := e.Extensions[graphqlFieldViolations].([]interface{})
+	for _, item := range violations {
+		v, _ := item.(map[string]interface{})
+		name, _ := v["field"].(string)
+		description, _ := v["description"].(string)
+		ge.details = append(ge.details, FieldViolation{Field: name, Description: description})
+	}
+	return ge
+}
+
+// graphqlHTTPStatus returns the HTTP status for an error code sent without one
+func graphqlHTTPStatus(code string) int {
+	switch code {
+	case "BAD_USER_INPUT", "GRAPHQL_PARSE_FAILED", "GRAPHQL_VALIDATION_FAILED":
+		return http.StatusBadRequest
+	case "":
+		return http.StatusInternalServerError
+	default:
+		return Error(code).HTTPCode()
+	}
+}
@@ -3,8 +3,13 @@ package errors
 import (
 	stderrors "errors"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type GRPCCoder interface {
@@ -55,24 +60,56 @@ func (e Error) GRPCCode() codes.Code {
 		return codes.InvalidArgument
 	case ErrUnauthorized:
 		return codes.Unauthenticated
+	case ErrPaymentRequired:
+		return codes.FailedPrecondition
 	case ErrForbidden:
 		return codes.PermissionDenied
 	case ErrMethodNotAllowed:
 		return codes.Unimplemented
+	case ErrNotAcceptable:
+		return codes.InvalidArgument
+	case ErrProxyAuthRequired:
+		return codes.Unauthenticated
 	case ErrRequestTimeout:
 		return codes.DeadlineExceeded
 	case ErrConflict:
 		return codes.AlreadyExists
 	case ErrGone:
 		return codes.NotFound
+	case ErrLengthRequired:
+		return codes.InvalidArgument
+	case ErrPreconditionFailed:
+		return codes.FailedPrecondition
+	case ErrPayloadTooLarge:
+		return codes.InvalidArgument
+	case ErrURITooLong:
+		return codes.InvalidArgument
 	case ErrUnsupportedMediaType:
 		return codes.InvalidArgument
+	case ErrRangeNotSatisfiable:
+		return codes.OutOfRange
+	case ErrExpectationFailed:
+		return codes.FailedPrecondition
 	case ErrImATeapot:
 		return codes.Unknown
+	case ErrMisdirectedRequest:
+		return codes.Unavailable
 	case ErrUnprocessableEntity:
 		return codes.InvalidArgument
+	case ErrLocked:
+		return codes.FailedPrecondition
+	case ErrFailedDependency:
+		return codes.FailedPrecondition
+	case ErrTooEarly:
+		return codes.Unavailable
+	case ErrUpgradeRequired:
+		return codes.FailedPrecondition
+	case ErrPreconditionRequired:
+		return codes.FailedPrecondition
 	case ErrTooManyRequests:
 		return codes.ResourceExhausted
+	case ErrRequestHeaderFieldsTooLarge:
+		return codes.InvalidArgument
 	case ErrUnavailableForLegalReasons:
 		return codes.Unavailable
 	case ErrInternalServerError:
@@ -85,31 +122,75 @@ func (e Error) GRPCCode() codes.Code {
 		return codes.Unavailable
 	case ErrGatewayTimeout:
 		return codes.DeadlineExceeded
+	case ErrHTTPVersionNotSupported:
+		return codes.Unimplemented
+	case ErrVariantAlsoNegotiates:
+		return codes.Internal
+	case ErrInsufficientStorage:
+		return codes.ResourceExhausted
+	case ErrLoopDetected:
+		return codes.Internal
+	case ErrNetworkAuthenticationRequired:
+		return codes.Unauthenticated
 	default:
 		return codes.Internal
 	}
 }
 
 func (e Error) GRPCStatus() *status.Status {
-	return errToStatus(e)
+	return ToStatus(e)
 }
 
 func (e embeddedError) GRPCStatus() *status.Status {
-	return errToStatus(e)
+	return ToStatus(e)
+}
+
+// statusError pairs an error with the status it will be sent as
+type statusError struct {
+	e error
+	s *status.Status
+}
+
+func (e statusError) Error() string {
+	return e.e.Error()
+}
+
+func (e statusError) Unwrap() error {
+	return e.e
+}
+
+func (e statusError) GRPCStatus() *status.Status {
+	return e.s
 }
 
 type grpcError struct {
-	gc codes.Code
-	hc int
-	m  string
-	t  string
-	s  *status.Status
+	gc        codes.Code
+	hc        int
+	m         string
+	t         string
+	s         *status.Status
+	help      []HelpLink
+	localized *LocalizedMessage
+	debug     *DebugInfo
+	cause     error         // the next coded layer or the original error when it was not a status
+	details   []interface{} // instance details such as the ID and fields
+	decoded   []error       // errors rebuilt from registered details
+	match     MatchMode
 }
 
 func (e grpcError) Error() string {
 	return e.m
 }
 
+// Unwrap returns the next coded layer followed by any errors rebuilt from registered details
+func (e grpcError) Unwrap() []error {
+	var errs []error
+	if e.cause != nil {
+		errs = append(errs, e.cause)
+	}
+	return append(errs, e.decoded...)
+}
+
 func (e grpcError) GRPCStatus() *status.Status {
 	return e.s
 }
@@ -127,17 +208,10 @@ func (e grpcError) TypeCode() string {
 }
 
 // Is returns true if any of TypeCoder, HTTPCoder, GRPCCoder are a match between the error and target
+//
+// Only the TypeCoder is compared when the error was received with MatchStrict.
 func (e grpcError) Is(target error) bool {
-	if t, ok := target.(GRPCCoder); ok && e.gc == t.GRPCCode() {
-		return true
-	}
-	if t, ok := target.(HTTPCoder); ok && e.hc == t.HTTPCode() {
-		return true
-	}
-	if t, ok := target.(TypeCoder); ok && e.t == t.TypeCode() {
-		return true
-	}
-	return false
+	return e.match.matches(e, target)
 }
 
 // GRPCCode returns the GRPC code for the given error or codes.OK when nil or codes.Unknown otherwise
@@ -149,72 +223,231 @@ func GRPCCode(err error) codes.Code {
 	if stderrors.As(err, &e) {
 		return e.GRPCCode()
 	}
+	if ce, ok := classify(err); ok {
+		return ce.GRPCCode()
+	}
 	return ErrUnknown.GRPCCode()
 }
 
 // SendGRPCError ensures that the error being used is sent with the correct code applied
 //
-// Use in the server when sending errors.
+// Use in the server when sending errors. Pass WithIncomingContext(ctx) to
+// pick the localized message using the locales requested by the client.
+// Statuses created by other packages are sent as they are, unless they are
+// server faults and WithMasking() is used; those are rebuilt with their
+// message masked. Errors received from another service are sent with the
+// details this package does not read, such as ErrorInfo or QuotaFailure,
+// forwarded unchanged, and keep the origin they were received with.
 // If err is nil then SendGRPCError returns nil.
-func SendGRPCError(err error) error {
+func SendGRPCError(err error, opts ...Option) error {
 	if err == nil {
 		return nil
 	}
 
-	// Already setup with a grpcCode
-	if _, ok := status.FromError(err); ok {
-		return err
+	// Already setup with a grpcCode by something other than a GRPCCoder; errors with details
+	// attached by this package also have a status but are built here with the options
+	var grpcCoder GRPCCoder
+	var detailed detailedError
+	if !stderrors.As(err, &grpcCoder) && !stderrors.As(err, &detailed) {
+		if s, ok := status.FromError(err); ok {
+			o := newOptions(opts)
+			if o.mask && isServerFault(s.Code()) {
+				return statusError{e: err, s: ToStatus(fromStatus(s, o), opts...)}
+			}
+			return err
+		}
 	}
 
-	s := errToStatus(err)
-
-	return s.Err()
+	return statusError{e: err, s: ToStatus(err, opts...)}
 }
 
 // ReceiveGRPCError recreates the error with the coded Error reapplied
 //
+// Statuses are converted with FromStatus().
 // Non-nil results can be used as both Error and *status.Status. Methods
 // errors.Is()/errors.As(), and status.Convert()/status.FromError() will
 // continue to work.
 //
+// Errors that are not a status, such as those from a canceled context, are
+// kept as the cause of the result so errors.Is() continues to match them.
+// context.Canceled and context.DeadlineExceeded are received as ErrCanceled
+// and ErrDeadlineExceeded, anything else as ErrUnknown.
+//
+// Received errors match errors.Is() targets using the mode set with
+// SetMatchMode() or WithMatchMode(). How much of the details sent by the
+// server are believed is decided by the TrustPolicy set with SetTrustPolicy()
+// or WithTrustPolicy().
+//
 // Use in the clients when receiving errors.
 // If err is nil then ReceiveGRPCError returns nil.
-func ReceiveGRPCError(err error) error {
+func ReceiveGRPCError(err error, opts ...Option) error {
 	if err == nil {
 		return nil
 	}
 
+	o := newOptions(opts)
+
 	s, ok := status.FromError(err)
 	if !ok {
+		// Not a status; keep the original error and classify what we can
+		code, _ := classify(err)
 		return &grpcError{
-			gc: ErrUnknown.GRPCCode(),
-			hc: ErrUnknown.HTTPCode(),
-			m:  err.Error(),
-			t:  ErrUnknown.TypeCode(),
-			s:  s,
+			gc:    code.GRPCCode(),
+			hc:    code.HTTPCode(),
+			m:     err.Error(),
+			t:     code.TypeCode(),
+			s:     status.New(code.GRPCCode(), err.Error()),
+			cause: err,
+			match: o.matchMode(),
 		}
 	}
 
+	return fromStatus(s, o)
+}
+
+// FromStatus converts a status into an error with the codes and details it was sent with
+//
+// The result is the same as ReceiveGRPCError() returns for s.Err(); it can be
+// used as both Error and *status.Status and takes the same options.
+// If s is nil or has the code codes.OK then FromStatus returns nil.
+func FromStatus(s *status.Status, opts ...Option) error {
+	if s == nil || s.Code() == codes.OK {
+		return nil
+	}
+	return fromStatus(s, newOptions(opts))
+}
+
+func fromStatus(s *status.Status, o *options) error {
 	grpcCode := s.Code()
-	httpCode := ErrUnknown.HTTPCode()
+	httpCode := codeToError(grpcCode).HTTPCode()
 	embedType := codeToError(grpcCode).TypeCode()
+	var help []HelpLink
+	var localized *LocalizedMessage
+	var debug *DebugInfo
+	var layers []*ErrorType
+	var details []interface{}
+	var decoded []error
+	var hints []interface{}
+
+	var received []interface{}
+	if o.acceptsDetails() {
+		received = s.Details()
+	}
+	trusted := o.trustsErrorTypes(s.Code(), received)
 
-	for _, detail := range s.Details() {
+	for _, detail := range received {
 		switch d := detail.(type) {
 		case *ErrorType:
+			if !trusted {
+				continue
+			}
+			o.checkHTTPCode(s.Code(), d)
 			embedType = d.TypeCode
 			grpcCode = codes.Code(d.GRPCCode)
 			httpCode = int(d.HTTPCode)
+			layers = append(layers, d)
+			details = errorTypeDetails(d)
+		case *errdetails.Help:
+			for _, link := range d.GetLinks() {
+				help = append(help, HelpLink{Description: link.GetDescription(), URL: link.GetUrl()})
+			}
+		case *errdetails.LocalizedMessage:
+			localized = &LocalizedMessage{Locale: d.GetLocale(), Message: d.GetMessage()}
+		case *errdetails.DebugInfo:
+			debug = &DebugInfo{Causes: splitCauses(d.GetDetail()), Stack: d.GetStackEntries()}
+		case *errdetails.RetryInfo:
+			// servers not using this package may still send how long to wait
+			if d.GetRetryDelay() != nil {
+				hints = append(hints, retryDelay(d.GetRetryDelay().AsDuration()))
+			}
+		default:
+			if err, ok := decodeDetail(detail); ok {
+				decoded = append(decoded, err)
+			}
 		}
 	}
 
+	// rebuild the cause chain from any layers sent before the outermost
+	var cause error
+	if len(layers) > 1 {
+		cause = layersToError(layers[:len(layers)-1], o.matchMode())
+	}
+
 	return &grpcError{
-		gc: grpcCode,
-		hc: httpCode,
-		m:  s.Message(),
-		s:  s,
-		t:  embedType,
+		gc:        grpcCode,
+		hc:        httpCode,
+		m:         s.Message(),
+		s:         s,
+		t:         embedType,
+		help:      help,
+		localized: localized,
+		debug:     debug,
+		cause:     cause,
+		details:   append(details, hints...),
+		decoded:   decoded,
+		match:     o.matchMode(),
+	}
+}
+
+// unreadDetails returns the details of the received status that were not read into the error
+func (e grpcError) unreadDetails() []*anypb.Any {
+	if e.s == nil {
+		return nil
+	}
+	var unread []*anypb.Any
+	for _, a := range e.s.Proto().GetDetails() {
+		m, err := a.UnmarshalNew()
+		if err == nil && readDetail(m) {
+			continue
+		}
+		unread = append(unread, a)
+	}
+	return unread
+}
+
+// readDetail returns true for the details that ReceiveGRPCError() reads into the error
+func readDetail(detail proto.Message) bool {
+	switch detail.(type) {
+	case *ErrorType, *errdetails.Help, *errdetails.LocalizedMessage, *errdetails.DebugInfo, *errdetails.RetryInfo:
+		return true
 	}
+	_, ok := decodeDetail(detail)
+	return ok
+}
+
+// the ErrorType version sent by this package
+const errorTypeVersion = 2
+
+// errorTypeDetails returns the instance details of a version 2 ErrorType
+//
+// Version 1 types leave the fields unset and result in no details.
+func errorTypeDetails(errType *ErrorType) []interface{} {
+	var details []interface{}
+	if errType.GetID() != "" {
+		details = append(details, errorID(errType.GetID()))
+	}
+	if errType.GetTimestamp() != nil {
+		details = append(details, sentAt(errType.GetTimestamp().AsTime()))
+	}
+	if errType.GetPublicMessage() != "" {
+		details = append(details, publicMessage(errType.GetPublicMessage()))
+	}
+	if errType.GetRetryDelay() != nil {
+		details = append(details, retryDelay(errType.GetRetryDelay().AsDuration()))
+	}
+	if errType.Retryable != nil {
+		details = append(details, retryable(errType.GetRetryable()))
+	}
+	for key, value := range errType.GetFields() {
+		details = append(details, field{key: key, value: value})
+	}
+	if errType.GetOrigin() != "" {
+		details = append(details, origin(errType.GetOrigin()))
+	}
+	if errType.GetTruncated() {
+		details = append(details, truncated(true))
+	}
+	return details
 }
 
 // convert a code to a known Error type;
@@ -259,42 +492,95 @@ func codeToError(code codes.Code) Error {
 	}
 }
 
-// convert an error into a gRPC *status.Status
-func errToStatus(err error) *status.Status {
-	grpcCode := ErrUnknown.GRPCCode()
-	httpCode := ErrUnknown.HTTPCode()
-	typeCode := ErrUnknown.TypeCode()
-
-	// Set the grpcCode based on GRPCCoder output; otherwise leave as Unknown
-	var grpcCoder GRPCCoder
-	if stderrors.As(err, &grpcCoder) {
-		grpcCode = grpcCoder.GRPCCode()
+// ToStatus converts an error into a status with its codes and details
+//
+// This is the status SendGRPCError() sends and takes the same options. Codes
+// missing from the error are taken from the type set with WithDefaultType(),
+// ErrUnknown otherwise.
+// If err is nil then ToStatus returns a status with the code codes.OK.
+func ToStatus(err error, opts ...Option) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
 	}
 
+	o := newOptions(opts)
+
+	grpcCode, httpCode, typeCode := o.codes(err)
+
 	// short circuit building detailed errors if the code is OK
 	if grpcCode == codes.OK {
 		return status.New(codes.OK, "")
 	}
 
-	// Set the httpCode based on HTTPCoder output; otherwise leave as Unknown
-	var httpCoder HTTPCoder
-	if stderrors.As(err, &httpCoder) {
-		httpCode = httpCoder.HTTPCode()
+	errInfo := &ErrorType{
+		TypeCode:      typeCode,
+		GRPCCode:      int64(grpcCode),
+		HTTPCode:      int64(httpCode),
+		Version:       errorTypeVersion,
+		ID:            ID(err),
+		PublicMessage: PublicMessage(err),
+		Fields:        Fields(err),
+		Origin:        o.originOf(err),
+	}
+	if o.stamp {
+		errInfo.Timestamp = timestamppb.Now()
+	}
+	if delay, ok := RetryDelay(err); ok {
+		errInfo.RetryDelay = durationpb.New(delay)
+	}
+	if hint, ok := lookup[retryable](err); ok {
+		errInfo.Retryable = proto.Bool(bool(hint))
 	}
 
-	// Embed the specific error "type"; otherwise leave as "UNKNOWN"
-	var typeCoder TypeCoder
-	if stderrors.As(err, &typeCoder) {
-		typeCode = typeCoder.TypeCode()
+	var details statusDetails
+
+	// Include the coded causes, innermost first, ahead of the outermost type
+	if o.chain {
+		layers := codedLayers(err)
+		for i := len(layers) - 1; i > 0; i-- {
+			layer := layers[i]
+			if o.mask && isServerFault(codes.Code(layer.GRPCCode)) {
+				layer.Message = maskedMessage(nil, int(layer.HTTPCode))
+			}
+			details.chain = append(details.chain, layer)
+		}
 	}
 
-	errInfo := &ErrorType{
-		TypeCode: typeCode,
-		GRPCCode: int64(grpcCode),
-		HTTPCode: int64(httpCode),
+	// Include documentation for the error; instance links first then registered links
+	if links := HelpLinks(err); len(links) != 0 {
+		help := &errdetails.Help{}
+		for _, link := range links {
+			help.Links = append(help.Links, &errdetails.Help_Link{Description: link.Description, Url: link.URL})
+		}
+		details.help = append(details.help, help)
+	}
+
+	// Include the user-facing message which best matches the requested locales
+	if localized, ok := Localized(err, o.locales...); ok {
+		details.localized = append(details.localized, &errdetails.LocalizedMessage{Locale: localized.Locale, Message: localized.Message})
+	}
+
+	// Include the details for any registered error types and from the detail encoders
+	details.registered = append(encodeDetails(err), o.encodeDetails(err)...)
+
+	// Include the details of a received error that were not read when it was received
+	var received *grpcError
+	if stderrors.As(err, &received) {
+		details.forwarded = received.unreadDetails()
+	}
+
+	// Include the cause chain and stack only when asked to
+	if o.debugInfo() {
+		if debug, ok := Debug(err); ok {
+			details.debug = append(details.debug, &errdetails.DebugInfo{StackEntries: debug.Stack, Detail: joinCauses(debug.Causes)})
+		}
 	}
 
-	s, _ := status.New(grpcCode, err.Error()).WithDetails(errInfo)
+	message := err.Error()
+	if o.mask && isServerFault(grpcCode) {
+		message = maskedMessage(err, httpCode)
+	}
 
-	return s
+	// Keep the status small enough to be sent as trailers
+	return fitStatus(grpcCode, message, errInfo, details, o.statusBudget())
 }
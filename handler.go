@@ -0,0 +1,161 @@
+package errors
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// HandlerFunc is an HTTP handler that returns an error instead of writing it
+//
+// HandlerFunc is itself an http.Handler which writes errors with WriteProblem().
+// Use Handler() to configure how errors are written and logged.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls h and writes any error it returns as a problem details response
+func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	Handler(h).ServeHTTP(w, r)
+}
+
+// Renderer writes an error to the response
+type Renderer func(w http.ResponseWriter, r *http.Request, err error)
+
+// WithRenderer sets the Renderer used by Handler() to write errors
+func WithRenderer(renderer Renderer) Option {
+	return func(o *options) {
+		o.renderer = renderer
+	}
+}
+
+// Handler returns an http.Handler that writes every error returned by h
+//
+// Errors are written by the Renderer set with WithRenderer(), or with
+// WriteProblem() and the same options otherwise. Retry-After and RateLimit headers are set for every
+// renderer with SetRetryHeaders(). Every error is passed to the hook set with
+// WithLogHook() first. Errors returned after h has already written the
+// response headers are only logged.
+func Handler(h HandlerFunc, opts ...Option) http.Handler {
+	o := newOptions(opts)
+	render := o.render(opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		if err := h(rw, r); err != nil {
+			o.respond(rw, r, err, render)
+		}
+	})
+}
+
+// Recover returns an http.Handler that turns panics in h into ErrInternalServerError responses
+//
+// The panic value and the stack of the panic are kept as the cause of the
+// error, whose message is "Internal Server Error"; use Debug() in the hook set
+// with WithLogHook() to log them. The error is written
+// the same way as by Handler() and takes the same options. Panics with
+// http.ErrAbortHandler are not recovered, so the server still aborts the
+// response. Panics after h has already written the response headers are
+// logged and then passed on as http.ErrAbortHandler, so that the server
+// aborts the response instead of ending it as if it were complete.
+func Recover(h http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+	render := o.render(opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			err := panicError(v)
+			if rw.written {
+				o.log(r.Context(), err)
+				panic(http.ErrAbortHandler)
+			}
+			o.respond(rw, r, err, render)
+		}()
+		h.ServeHTTP(rw, r)
+	})
+}
+
+// panicError returns the ErrInternalServerError for a recovered panic with the stack of the panic
+//
+// The value of the panic is only kept in the cause; it is not meant for clients.
+func panicError(v interface{}) error {
+	cause, ok := v.(error)
+	if !ok {
+		cause = fmt.Errorf("panic: %v", v)
+	}
+	// skip panicError, the deferred function and runtime.gopanic so the stack starts at the panicking function
+	message := http.StatusText(http.StatusInternalServerError)
+	return WithPublicMessage(ErrInternalServerError.Wrap(attach(cause, callers(5)), message), message)
+}
+
+// render returns the Renderer set with WithRenderer() or one that uses WriteProblem() with opts
+func (o *options) render(opts []Option) Renderer {
+	if o.renderer != nil {
+		return o.renderer
+	}
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		WriteProblem(w, r, err, opts...)
+	}
+}
+
+// respond logs the error and renders it when the response has not been started
+func (o *options) respond(w *responseWriter, r *http.Request, err error, render Renderer) {
+	o.log(r.Context(), err)
+	if w.written {
+		return
+	}
+	SetRetryHeaders(w.Header(), err)
+	render(w.ResponseWriter, r, err)
+}
+
+// responseWriter records whether the response headers have been written
+type responseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	// informational headers may be followed by the final status
+	if code >= http.StatusOK {
+		w.written = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the original ResponseWriter for use by http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush sends any buffered data to the client
+func (w *responseWriter) Flush() {
+	w.written = true
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection; the response is then taken to have been written
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.written = true
+	}
+	return conn, rw, err
+}
@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"sync"
+)
+
+// HelpLink points to documentation that explains an error
+type HelpLink struct {
+	Description string
+	URL         string
+}
+
+var registeredHelp = struct {
+	sync.RWMutex
+	links map[string][]HelpLink
+}{links: map[string][]HelpLink{}}
+
+// RegisterHelp registers a documentation link for every error sharing the type code of typeCoder
+//
+// Registered links are included after any links attached to the error instance
+// with WithHelp().
+func RegisterHelp(typeCoder TypeCoder, description, url string) {
+	registeredHelp.Lock()
+	defer registeredHelp.Unlock()
+
+	typeCode := typeCoder.TypeCode()
+	registeredHelp.links[typeCode] = append(registeredHelp.links[typeCode], HelpLink{
+		Description: description,
+		URL:         url,
+	})
+}
+
+// WithHelp attaches a documentation link to the error
+// If err is nil then WithHelp returns nil
+func WithHelp(err error, description, url string) error {
+	return attach(err, HelpLink{Description: description, URL: url})
+}
+
+// HelpLinks returns the links attached to the error followed by the links registered for its type code
+//
+// Links are returned in the order they were attached, outermost first, and
+// duplicate URLs are only returned once.
+func HelpLinks(err error) []HelpLink {
+	if err == nil {
+		return nil
+	}
+
+	var links []HelpLink
+	seen := map[string]bool{}
+	add := func(link HelpLink) {
+		if seen[link.URL] {
+			return
+		}
+		seen[link.URL] = true
+		links = append(links, link)
+	}
+
+	walk(err, func(err error) bool {
+		switch e := err.(type) {
+		case detailedError:
+			if link, ok := e.d.(HelpLink); ok {
+				add(link)
+			}
+		case *grpcError:
+			for _, link := range e.help {
+				add(link)
+			}
+		}
+		return true
+	})
+
+	registeredHelp.RLock()
+	defer registeredHelp.RUnlock()
+	for _, link := range registeredHelp.links[TypeCode(err)] {
+		add(link)
+	}
+
+	return links
+}
+
+// HelpURL returns the URL of the first help link for the error or blank when there are none
+//
+// HTTP responses may use the URL as the problem "type" or in a Link header.
+func HelpURL(err error) string {
+	if links := HelpLinks(err); len(links) != 0 {
+		return links[0].URL
+	}
+	return ""
+}
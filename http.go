@@ -1,10 +1,22 @@
 package errors
 
 import (
+	"bytes"
 	stderrors "errors"
+	"io"
+	"mime"
 	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// TypeCodeHeader is the response header that carries the type code of an error
+const TypeCodeHeader = "X-Type-Code"
+
+// the most of an error response body that is read into memory
+const maxErrorBodySize = 1 << 20
+
 type HTTPCoder interface {
 	error
 	HTTPCode() int
@@ -53,24 +65,56 @@ func (e Error) HTTPCode() int {
 		return http.StatusBadRequest
 	case ErrUnauthorized:
 		return http.StatusUnauthorized
+	case ErrPaymentRequired:
+		return http.StatusPaymentRequired
 	case ErrForbidden:
 		return http.StatusForbidden
 	case ErrMethodNotAllowed:
 		return http.StatusMethodNotAllowed
+	case ErrNotAcceptable:
+		return http.StatusNotAcceptable
+	case ErrProxyAuthRequired:
+		return http.StatusProxyAuthRequired
 	case ErrRequestTimeout:
 		return http.StatusRequestTimeout
 	case ErrConflict:
 		return http.StatusConflict
 	case ErrGone:
 		return http.StatusGone
+	case ErrLengthRequired:
+		return http.StatusLengthRequired
+	case ErrPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case ErrPayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case ErrURITooLong:
+		return http.StatusRequestURITooLong
 	case ErrUnsupportedMediaType:
 		return http.StatusUnsupportedMediaType
+	case ErrRangeNotSatisfiable:
+		return http.StatusRequestedRangeNotSatisfiable
+	case ErrExpectationFailed:
+		return http.StatusExpectationFailed
 	case ErrImATeapot:
 		return 418 // teapot support
+	case ErrMisdirectedRequest:
+		return http.StatusMisdirectedRequest
 	case ErrUnprocessableEntity:
 		return http.StatusUnprocessableEntity
+	case ErrLocked:
+		return http.StatusLocked
+	case ErrFailedDependency:
+		return http.StatusFailedDependency
+	case ErrTooEarly:
+		return http.StatusTooEarly
+	case ErrUpgradeRequired:
+		return http.StatusUpgradeRequired
+	case ErrPreconditionRequired:
+		return http.StatusPreconditionRequired
 	case ErrTooManyRequests:
 		return http.StatusTooManyRequests
+	case ErrRequestHeaderFieldsTooLarge:
+		return http.StatusRequestHeaderFieldsTooLarge
 	case ErrUnavailableForLegalReasons:
 		return http.StatusUnavailableForLegalReasons
 	case ErrInternalServerError:
@@ -83,6 +127,16 @@ func (e Error) HTTPCode() int {
 		return http.StatusServiceUnavailable
 	case ErrGatewayTimeout:
 		return http.StatusGatewayTimeout
+	case ErrHTTPVersionNotSupported:
+		return http.StatusHTTPVersionNotSupported
+	case ErrVariantAlsoNegotiates:
+		return http.StatusVariantAlsoNegotiates
+	case ErrInsufficientStorage:
+		return http.StatusInsufficientStorage
+	case ErrLoopDetected:
+		return http.StatusLoopDetected
+	case ErrNetworkAuthenticationRequired:
+		return http.StatusNetworkAuthenticationRequired
 	default:
 		return http.StatusInternalServerError
 	}
@@ -98,5 +152,223 @@ func HTTPCode(err error) int {
 	if stderrors.As(err, &e) {
 		return e.HTTPCode()
 	}
+	if ce, ok := classify(err); ok {
+		return ce.HTTPCode()
+	}
 	return ErrUnknown.HTTPCode()
 }
+
+// httpCodeToError returns the Error for an HTTP status received without a type code
+func httpCodeToError(code int) Error {
+	switch code {
+	case http.StatusOK:
+		return ErrOK
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusPaymentRequired:
+		return ErrPaymentRequired
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusMethodNotAllowed:
+		return ErrMethodNotAllowed
+	case http.StatusNotAcceptable:
+		return ErrNotAcceptable
+	case http.StatusProxyAuthRequired:
+		return ErrProxyAuthRequired
+	case http.StatusRequestTimeout:
+		return ErrRequestTimeout
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusGone:
+		return ErrGone
+	case http.StatusLengthRequired:
+		return ErrLengthRequired
+	case http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	case http.StatusRequestEntityTooLarge:
+		return ErrPayloadTooLarge
+	case http.StatusRequestURITooLong:
+		return ErrURITooLong
+	case http.StatusUnsupportedMediaType:
+		return ErrUnsupportedMediaType
+	case http.StatusRequestedRangeNotSatisfiable:
+		return ErrRangeNotSatisfiable
+	case http.StatusExpectationFailed:
+		return ErrExpectationFailed
+	case 418:
+		return ErrImATeapot
+	case http.StatusMisdirectedRequest:
+		return ErrMisdirectedRequest
+	case http.StatusUnprocessableEntity:
+		return ErrUnprocessableEntity
+	case http.StatusLocked:
+		return ErrLocked
+	case http.StatusFailedDependency:
+		return ErrFailedDependency
+	case http.StatusTooEarly:
+		return ErrTooEarly
+	case http.StatusUpgradeRequired:
+		return ErrUpgradeRequired
+	case http.StatusPreconditionRequired:
+		return ErrPreconditionRequired
+	case http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case http.StatusRequestHeaderFieldsTooLarge:
+		return ErrRequestHeaderFieldsTooLarge
+	case http.StatusUnavailableForLegalReasons:
+		return ErrUnavailableForLegalReasons
+	case http.StatusInternalServerError:
+		return ErrInternalServerError
+	case http.StatusNotImplemented:
+		return ErrNotImplemented
+	case http.StatusBadGateway:
+		return ErrBadGateway
+	case http.StatusServiceUnavailable:
+		return ErrServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrGatewayTimeout
+	case http.StatusHTTPVersionNotSupported:
+		return ErrHTTPVersionNotSupported
+	case http.StatusVariantAlsoNegotiates:
+		return ErrVariantAlsoNegotiates
+	case http.StatusInsufficientStorage:
+		return ErrInsufficientStorage
+	case http.StatusLoopDetected:
+		return ErrLoopDetected
+	case http.StatusNetworkAuthenticationRequired:
+		return ErrNetworkAuthenticationRequired
+	case http.StatusNotExtended:
+		return ErrUnknown
+	default:
+		if code >= 400 && code < 500 {
+			return ErrBadRequest
+		}
+		return ErrInternalServerError
+	}
+}
+
+// errorForHTTP returns the Error used for the GRPC code of an error received over HTTP
+//
+// Type codes of the Errors in this package are used when the HTTP status agrees
+// with them; otherwise the Error for the HTTP status is used.
+func errorForHTTP(typeCode string, httpCode int) Error {
+	if e := Error(typeCode); e.HTTPCode() == httpCode {
+		return e
+	}
+	return httpCodeToError(httpCode)
+}
+
+// ReceiveHTTPError recreates the coded error from an HTTP response
+//
+// The type code is read from an application/problem+json body, a JSON:API
+// errors document or the TypeCodeHeader, and otherwise comes from the Error
+// for the status code. The result behaves like errors returned by
+// ReceiveGRPCError(): TypeCode(), HTTPCode(), GRPCCode() and errors.Is() work
+// as they would on the server, and WithMatchMode() may be used. JSON:API
+// documents with more than one error are received as joined errors.
+// The status of the response is used even when a body gives another one.
+// Retry-After and RateLimit headers are available from RetryDelay() and
+// Quota(). The body is left readable. The host and path of the request that
+// got the response are passed to AcceptDetails of the TrustPolicy; responses
+// whose details are not accepted are received with only their status.
+// If resp is nil or its status is not an error then ReceiveHTTPError returns nil.
+func ReceiveHTTPError(resp *http.Response, opts ...Option) error {
+	if resp == nil || resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+
+	if req := resp.Request; req != nil && req.URL != nil {
+		opts = append([]Option{WithPeer(req.URL.Host, req.URL.Path)}, opts...)
+	}
+	o := newOptions(opts)
+	if !o.acceptsDetails() {
+		return httpError("", resp.StatusCode, "", o)
+	}
+	typeCode := resp.Header.Get(TypeCodeHeader)
+
+	var errs []*grpcError
+	switch mediaType, data := readErrorBody(resp); mediaType {
+	case ProblemContentType:
+		if p, err := ParseProblem(bytes.NewReader(data)); err == nil {
+			// the status of the response is what the client acted on; a
+			// body that disagrees with it is not trusted
+			p.Status = resp.StatusCode
+			errs = append(errs, p.err(typeCode, o))
+		}
+	case JSONAPIContentType:
+		if d, err := ParseJSONAPIErrors(bytes.NewReader(data)); err == nil {
+			errs = d.errs(resp.StatusCode, o)
+		}
+	}
+	if len(errs) == 0 {
+		errs = append(errs, httpError(typeCode, resp.StatusCode, "", o))
+	}
+
+	// hints in the body come first and take precedence over the headers
+	hints := retryHeaderDetails(resp.Header)
+	joined := make([]error, len(errs))
+	for i, e := range errs {
+		e.details = append(e.details, hints...)
+		joined[i] = e
+	}
+	if len(joined) == 1 {
+		return joined[0]
+	}
+	return stderrors.Join(joined...)
+}
+
+// readErrorBody reads an error body in a media type this package understands and puts what was read back in front of the body
+func readErrorBody(resp *http.Response) (string, []byte) {
+	if resp.Body == nil {
+		return "", nil
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || (mediaType != ProblemContentType && mediaType != JSONAPIContentType) {
+		return "", nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	if err != nil {
+		return "", nil
+	}
+	return mediaType, data
+}
+
+// httpError returns a received error for the HTTP status; missing type codes and messages come from the status
+//
+// Statuses that are not errors are received as http.StatusInternalServerError
+// so that the result never converts to codes.OK.
+func httpError(typeCode string, httpCode int, message string, o *options) *grpcError {
+	if httpCode < http.StatusBadRequest {
+		httpCode = http.StatusInternalServerError
+	}
+	if typeCode == "" || Error(typeCode) == ErrOK {
+		typeCode = httpCodeToError(httpCode).TypeCode()
+	}
+	if message == "" {
+		message = http.StatusText(httpCode)
+	}
+	if message == "" {
+		message = typeCode
+	}
+	grpcCode := errorForHTTP(typeCode, httpCode).GRPCCode()
+	if grpcCode == codes.OK {
+		grpcCode = ErrUnknown.GRPCCode()
+	}
+	return &grpcError{
+		gc:    grpcCode,
+		hc:    httpCode,
+		m:     message,
+		t:     typeCode,
+		s:     status.New(grpcCode, message),
+		match: o.matchMode(),
+	}
+}
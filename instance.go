@@ -0,0 +1,167 @@
+package errors
+
+import "time"
+
+// details attached to single error instances
+type (
+	errorID       string
+	publicMessage string
+	retryDelay    time.Duration
+	retryable     bool
+	field         struct{ key, value string }
+	sentAt        time.Time
+	origin        string
+	truncated     bool
+)
+
+// WithID attaches an ID that identifies this instance of the error, e.g. for correlating logs
+// If err is nil then WithID returns nil
+func WithID(err error, id string) error {
+	return attach(err, errorID(id))
+}
+
+// ID returns the instance ID of the error or blank when there is none
+func ID(err error) string {
+	id, _ := lookup[errorID](err)
+	return string(id)
+}
+
+// WithPublicMessage attaches a message that is safe to show to end users
+//
+// The public message is used in place of masked messages.
+// If err is nil then WithPublicMessage returns nil
+func WithPublicMessage(err error, message string) error {
+	return attach(err, publicMessage(message))
+}
+
+// PublicMessage returns the public message of the error or blank when there is none
+func PublicMessage(err error) string {
+	message, _ := lookup[publicMessage](err)
+	return string(message)
+}
+
+// WithField attaches a key/value pair that describes the error
+// If err is nil then WithField returns nil
+func WithField(err error, key, value string) error {
+	return attach(err, field{key: key, value: value})
+}
+
+// Fields returns every field attached to the error; outer values replace inner values for the same key
+func Fields(err error) map[string]string {
+	var fields map[string]string
+	walk(err, func(err error) bool {
+		var found []interface{}
+		switch e := err.(type) {
+		case detailedError:
+			found = []interface{}{e.d}
+		case *grpcError:
+			found = e.details
+		}
+		for _, d := range found {
+			f, ok := d.(field)
+			if !ok {
+				continue
+			}
+			if fields == nil {
+				fields = map[string]string{}
+			}
+			if _, exists := fields[f.key]; !exists {
+				fields[f.key] = f.value
+			}
+		}
+		return true
+	})
+	return fields
+}
+
+// WithRetryDelay attaches how long a client should wait before retrying
+//
+// Errors with a retry delay are retryable unless WithRetryable(err, false) is used.
+// If err is nil then WithRetryDelay returns nil
+func WithRetryDelay(err error, delay time.Duration) error {
+	return attach(err, retryDelay(delay))
+}
+
+// RetryDelay returns how long a client should wait before retrying
+func RetryDelay(err error) (time.Duration, bool) {
+	delay, ok := lookup[retryDelay](err)
+	return time.Duration(delay), ok
+}
+
+// WithRetryable attaches whether the failed call may be retried
+// If err is nil then WithRetryable returns nil
+func WithRetryable(err error, isRetryable bool) error {
+	return attach(err, retryable(isRetryable))
+}
+
+// retryableTypes are the type codes of errors that may be retried without a hint
+var retryableTypes = map[string]bool{
+	ErrAborted.TypeCode():            true,
+	ErrResourceExhausted.TypeCode():  true,
+	ErrUnavailable.TypeCode():        true,
+	ErrServiceUnavailable.TypeCode(): true,
+	ErrTooManyRequests.TypeCode():    true,
+}
+
+// Retryable returns true if the failed call may be retried
+//
+// Hints attached with WithRetryable() or WithRetryDelay(), or received from
+// the server, are used first; otherwise only errors with the type codes of
+// ErrAborted, ErrResourceExhausted, ErrUnavailable, ErrServiceUnavailable or
+// ErrTooManyRequests are considered retryable. Other errors sharing their GRPC
+// codes, such as ErrUnavailableForLegalReasons or ErrInsufficientStorage, are
+// not.
+func Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if hint, ok := lookup[retryable](err); ok {
+		return bool(hint)
+	}
+	if _, ok := RetryDelay(err); ok {
+		return true
+	}
+	return retryableTypes[TypeCode(err)]
+}
+
+// Timestamp returns when a received error was sent or the zero time otherwise
+func Timestamp(err error) time.Time {
+	t, _ := lookup[sentAt](err)
+	return time.Time(t)
+}
+
+// Origin returns the name of the service that sent a received error or blank otherwise
+//
+// Servers set their name with WithOrigin().
+func Origin(err error) string {
+	name, _ := lookup[origin](err)
+	return string(name)
+}
+
+// lookup returns the outermost detail of type T attached to or received with the error
+func lookup[T any](err error) (T, bool) {
+	var found T
+	var ok bool
+	walk(err, func(err error) bool {
+		switch e := err.(type) {
+		case detailedError:
+			found, ok = e.d.(T)
+		case *grpcError:
+			for _, d := range e.details {
+				if found, ok = d.(T); ok {
+					break
+				}
+			}
+		}
+		return !ok
+	})
+	return found, ok
+}
+
+// Truncated returns true if the server left details out of a received error or shortened its message
+//
+// Servers keep statuses within the budget set with SetStatusBudget() or WithStatusBudget().
+func Truncated(err error) bool {
+	t, _ := lookup[truncated](err)
+	return bool(t)
+}
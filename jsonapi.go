@@ -0,0 +1,332 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// JSONAPIContentType is the media type of JSON:API documents
+const JSONAPIContentType = "application/vnd.api+json"
+
+// the JSON pointer prefix of the attributes of the primary resource
+const jsonapiAttributes = "/data/attributes/"
+
+// JSONAPIErrors is a JSON:API document with an array of error objects
+type JSONAPIErrors struct {
+	Errors []JSONAPIError `json:"errors"`
+}
+
+// JSONAPIError is a JSON:API error object
+type JSONAPIError struct {
+	ID     string                 `json:"id,omitempty"`
+	Links  *JSONAPILinks          `json:"links,omitempty"`
+	Status string                 `json:"status,omitempty"`
+	Code   string                 `json:"code,omitempty"`
+	Title  string                 `json:"title,omitempty"`
+	Detail string                 `json:"detail,omitempty"`
+	Source *JSONAPISource         `json:"source,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+// JSONAPILinks are the links of a JSON:API error object
+type JSONAPILinks struct {
+	About string `json:"about,omitempty"`
+}
+
+// JSONAPISource is the part of the request that caused a JSON:API error
+type JSONAPISource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Header    string `json:"header,omitempty"`
+}
+
+// NewJSONAPIErrors returns the JSON:API errors document for the error
+//
+// Joined errors are given an error object each. Each object has:
+//
+//   - status is the HTTP code of the error
+//   - code is the type code of the error
+//   - title is the text of the HTTP status
+//   - detail is the public message; otherwise the localized message that best
+//     matches the locales given with WithLocale(), or the status text. The
+//     message of the error is never used as it may reveal server internals.
+//   - id is the ID of the error
+//   - links.about is the URL of the first help link
+//   - meta holds the fields attached to the error
+//
+// Errors with field violations are given an error object for each violation
+// instead, with the description as the detail and the field as the
+// source.pointer. Fields are taken to be attributes of the primary resource, so
+// "user.email" becomes "/data/attributes/user/email"; fields that start with a
+// "/" are used as they are.
+// If err is nil then NewJSONAPIErrors returns nil.
+func NewJSONAPIErrors(err error, opts ...Option) *JSONAPIErrors {
+	if err == nil {
+		return nil
+	}
+
+	o := newOptions(opts)
+
+	d := &JSONAPIErrors{}
+	for _, err := range joinedErrors(err) {
+		_, httpCode, typeCode := o.codes(err)
+
+		e := JSONAPIError{
+			ID:     ID(err),
+			Status: strconv.Itoa(httpCode),
+			Code:   typeCode,
+			Title:  http.StatusText(httpCode),
+			Detail: o.publicDetail(err, httpCode),
+		}
+		if url := HelpURL(err); url != "" {
+			e.Links = &JSONAPILinks{About: url}
+		}
+		for key, value := range Fields(err) {
+			if e.Meta == nil {
+				e.Meta = map[string]interface{}{}
+			}
+			e.Meta[key] = value
+		}
+
+		violations := FieldViolations(err)
+		if len(violations) == 0 {
+			d.Errors = append(d.Errors, e)
+			continue
+		}
+		for _, v := range violations {
+			e.Detail = v.Description
+			e.Source = &JSONAPISource{Pointer: jsonPointer(v.Field)}
+			d.Errors = append(d.Errors, e)
+		}
+	}
+	return d
+}
+
+// WriteJSONAPIErrors writes the error as a JSON:API errors document
+//
+// The document is built with NewJSONAPIErrors() using the locales from the
+// Accept-Language header of the request. The response status is the status
+// shared by every error object; otherwise 400 when they are all client errors
+// or 500. Retry-After and RateLimit headers are set with SetRetryHeaders().
+// Bodies are not written for HEAD requests.
+// If err is nil then nothing is written.
+func WriteJSONAPIErrors(w http.ResponseWriter, r *http.Request, err error, opts ...Option) {
+	if err == nil {
+		return
+	}
+	if r != nil {
+		if accept := r.Header.Get("Accept-Language"); accept != "" {
+			opts = append([]Option{WithLocale(parseAcceptLanguage(accept)...)}, opts...)
+		}
+	}
+
+	d := NewJSONAPIErrors(err, opts...)
+	var body bytes.Buffer
+	if encodeErr := json.NewEncoder(&body).Encode(d); encodeErr != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", JSONAPIContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	SetRetryHeaders(w.Header(), err)
+	w.WriteHeader(d.status())
+	if r != nil && r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(body.Bytes())
+}
+
+// JSONAPIRenderer returns a Renderer that writes errors with WriteJSONAPIErrors()
+func JSONAPIRenderer(opts ...Option) Renderer {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		WriteJSONAPIErrors(w, r, err, opts...)
+	}
+}
+
+// ParseJSONAPIErrors reads a JSON:API errors document
+func ParseJSONAPIErrors(r io.Reader) (*JSONAPIErrors, error) {
+	d := &JSONAPIErrors{}
+	if err := json.NewDecoder(r).Decode(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Err converts the error objects back into coded errors
+//
+// The code is used as the type code. Objects without one are given the type
+// code of the Error for their status. Consecutive objects with the same id,
+// code and status that each have a source.pointer are received as a single
+// error with a field violation for each. More than one error is returned as
+// joined errors. The results support the same functions as errors received
+// over GRPC, including errors.Is(), ID(), Fields(), HelpLinks() and
+// FieldViolations().
+// If d is nil or has no errors then Err returns nil.
+func (d *JSONAPIErrors) Err() error {
+	if d == nil {
+		return nil
+	}
+	errs := d.errs(0, newOptions(nil))
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	joined := make([]error, len(errs))
+	for i, e := range errs {
+		joined[i] = e
+	}
+	return stderrors.Join(joined...)
+}
+
+// errs converts the error objects using httpCode for objects without a status
+func (d *JSONAPIErrors) errs(httpCode int, o *options) []*grpcError {
+	var errs []*grpcError
+	var last *JSONAPIError
+	for i := range d.Errors {
+		e := &d.Errors[i]
+		violation := e.violation()
+		if last != nil && violation != nil && last.violation() != nil && e.ID == last.ID && e.Code == last.Code && e.Status == last.Status {
+			prev := errs[len(errs)-1]
+			prev.details = append(prev.details, *violation)
+			continue
+		}
+		errs = append(errs, e.err(httpCode, o))
+		last = e
+	}
+	return errs
+}
+
+// status returns the status shared by every error object, otherwise 400 for client errors or 500
+func (d *JSONAPIErrors) status() int {
+	status := 0
+	for _, e := range d.Errors {
+		code, _ := strconv.Atoi(e.Status)
+		switch {
+		case status == 0 || status == code:
+			status = code
+		case code >= 400 && code < 500 && status >= 400 && status < 500:
+			status = http.StatusBadRequest
+		default:
+			status = http.StatusInternalServerError
+		}
+	}
+	if status < http.StatusBadRequest {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+// err converts the error object using httpCode when it has no status
+func (e JSONAPIError) err(httpCode int, o *options) *grpcError {
+	if code, err := strconv.Atoi(e.Status); err == nil && code >= http.StatusBadRequest {
+		httpCode = code
+	}
+	if httpCode == 0 {
+		httpCode = http.StatusInternalServerError
+	}
+
+	message := e.Detail
+	if message == "" {
+		message = e.Title
+	}
+
+	var details []interface{}
+	if e.ID != "" {
+		details = append(details, errorID(e.ID))
+	}
+	if violation := e.violation(); violation != nil {
+		details = append(details, *violation)
+	} else if e.Detail != "" {
+		details = append(details, publicMessage(e.Detail))
+	}
+	for key, value := range e.Meta {
+		if s, ok := value.(string); ok {
+			details = append(details, field{key: key, value: s})
+		}
+	}
+
+	ge := httpError(e.Code, httpCode, message, o)
+	if e.Links != nil && e.Links.About != "" {
+		ge.help = []HelpLink{{URL: e.Links.About}}
+	}
+	ge.details = details
+	return ge
+}
+
+// violation returns the field violation of an error object with a source.pointer
+func (e JSONAPIError) violation() *FieldViolation {
+	if e.Source == nil || e.Source.Pointer == "" {
+		return nil
+	}
+	return &FieldViolation{Field: fieldPath(e.Source.Pointer), Description: e.Detail}
+}
+
+// joinedErrors returns the errors joined together in err or err itself
+//
+// Details attached to joined errors, such as an ID, are attached to each of them.
+func joinedErrors(err error) []error {
+	switch e := err.(type) {
+	case embeddedError, *grpcError:
+		return []error{err}
+	case detailedError:
+		errs := joinedErrors(e.e)
+		if len(errs) == 1 {
+			return []error{err}
+		}
+		for i, joined := range errs {
+			errs[i] = detailedError{e: joined, d: e.d}
+		}
+		return errs
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, err := range joined.Unwrap() {
+		errs = append(errs, joinedErrors(err)...)
+	}
+	return errs
+}
+
+// escape and unescape "~" and "/" in the reference tokens of JSON pointers; see RFC 6901
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// jsonPointer returns the JSON pointer for the attribute named by the field
+//
+// Each "." separated name in the field is one reference token of the pointer.
+// Fields that already are pointers are used as they are.
+func jsonPointer(field string) string {
+	if strings.HasPrefix(field, "/") {
+		return field
+	}
+	tokens := strings.Split(field, ".")
+	for i, token := range tokens {
+		tokens[i] = pointerEscaper.Replace(token)
+	}
+	return jsonapiAttributes + strings.Join(tokens, "/")
+}
+
+// fieldPath returns the field for a JSON pointer to an attribute or the pointer otherwise
+func fieldPath(pointer string) string {
+	attribute, ok := strings.CutPrefix(pointer, jsonapiAttributes)
+	if !ok {
+		return pointer
+	}
+	tokens := strings.Split(attribute, "/")
+	for i, token := range tokens {
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+	return strings.Join(tokens, ".")
+}
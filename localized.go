@@ -0,0 +1,111 @@
+package errors
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LocalizedMessage is a user-facing message in a specific locale, e.g. "en-US"
+type LocalizedMessage struct {
+	Locale  string
+	Message string
+}
+
+// WithLocalizedMessage attaches a user-facing message for the locale to the error
+//
+// Messages for any number of locales may be attached. The technical message
+// returned by Error() is not altered.
+// If err is nil then WithLocalizedMessage returns nil
+func WithLocalizedMessage(err error, locale, message string) error {
+	return attach(err, LocalizedMessage{Locale: locale, Message: message})
+}
+
+// Localized returns the localized message which best matches the preferred locales
+//
+// Locales are matched exactly first and then by language alone, so "en-GB" will
+// match a message for "en". When no locale matches, or none are given, the
+// outermost message is returned. Received errors have at most one localized message.
+func Localized(err error, locales ...string) (LocalizedMessage, bool) {
+	var messages []LocalizedMessage
+	walk(err, func(err error) bool {
+		switch e := err.(type) {
+		case detailedError:
+			if message, ok := e.d.(LocalizedMessage); ok {
+				messages = append(messages, message)
+			}
+		case *grpcError:
+			if e.localized != nil {
+				messages = append(messages, *e.localized)
+			}
+		}
+		return true
+	})
+
+	if len(messages) == 0 {
+		return LocalizedMessage{}, false
+	}
+
+	for _, locale := range locales {
+		for _, message := range messages {
+			if strings.EqualFold(message.Locale, locale) {
+				return message, true
+			}
+		}
+		for _, message := range messages {
+			if strings.EqualFold(language(message.Locale), language(locale)) {
+				return message, true
+			}
+		}
+	}
+
+	return messages[0], true
+}
+
+// language returns the primary language subtag of a locale
+func language(locale string) string {
+	if i := strings.IndexAny(locale, "-_"); i != -1 {
+		return locale[:i]
+	}
+	return locale
+}
+
+// parseAcceptLanguage returns the locales from an Accept-Language value ordered by quality
+func parseAcceptLanguage(value string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(value, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale := strings.TrimSpace(fields[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		ranges = append(ranges, weighted{locale: locale, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	locales := make([]string, len(ranges))
+	for i, r := range ranges {
+		locales[i] = r.locale
+	}
+	return locales
+}
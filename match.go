@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"sync/atomic"
+)
+
+// MatchMode decides how errors received over GRPC are matched by errors.Is()
+type MatchMode int32
+
+const (
+	// MatchEquivalent matches when any of the Type, HTTP or GRPC codes are equal
+	//
+	// Errors that share a code are treated as equivalent. A received ErrAlreadyExists
+	// will match ErrConflict and ErrAborted, which share HTTP 409, and a received
+	// ErrBadRequest will match ErrInvalidArgument, which share codes.InvalidArgument.
+	// This is the default.
+	MatchEquivalent MatchMode = iota
+	// MatchStrict matches only when the Type codes are equal
+	MatchStrict
+)
+
+var matchMode atomic.Int32
+
+// SetMatchMode sets how received errors are matched when WithMatchMode() is not used
+//
+// The mode is applied when the error is received.
+func SetMatchMode(mode MatchMode) {
+	matchMode.Store(int32(mode))
+}
+
+// WithMatchMode overrides SetMatchMode() for the errors being received
+func WithMatchMode(mode MatchMode) Option {
+	return func(o *options) {
+		o.match = &mode
+	}
+}
+
+func (o *options) matchMode() MatchMode {
+	if o.match != nil {
+		return *o.match
+	}
+	return MatchMode(matchMode.Load())
+}
+
+// matches returns true if the received codes match the target under the mode
+func (m MatchMode) matches(e grpcError, target error) bool {
+	if t, ok := target.(TypeCoder); ok && e.t == t.TypeCode() {
+		return true
+	}
+	if m == MatchStrict {
+		return false
+	}
+	if t, ok := target.(GRPCCoder); ok && e.gc == t.GRPCCode() {
+		return true
+	}
+	if t, ok := target.(HTTPCoder); ok && e.hc == t.HTTPCode() {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"html/template"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// Option configures how errors are converted into and out of a *status.Status
+type Option func(*options)
+
+type options struct {
+	locales []string
+	debug   *bool
+	mask    bool
+	chain   bool
+	origin  string
+	stamp   bool
+	logHook func(ctx context.Context, err error)
+	match   *MatchMode
+	trust   *TrustPolicy
+	target  string
+	method  string
+	budget  *int
+
+	defaultType *Error
+	encoders    []func(err error) proto.Message
+	renderer    Renderer
+	mediaTypes  []mediaType
+	html        *template.Template
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithLocale sets the preferred locales, most preferred first, used to pick a localized message
+func WithLocale(locales ...string) Option {
+	return func(o *options) {
+		o.locales = append(o.locales, locales...)
+	}
+}
+
+// WithDebugInfo overrides EnableDebugInfo() to include or leave out the DebugInfo detail
+func WithDebugInfo(enabled bool) Option {
+	return func(o *options) {
+		o.debug = &enabled
+	}
+}
+
+func (o *options) debugInfo() bool {
+	if o.debug != nil {
+		return *o.debug
+	}
+	return debugInfo.Load()
+}
+
+// WithOrigin sets the name of the service that is sending the error
+func WithOrigin(service string) Option {
+	return func(o *options) {
+		o.origin = service
+	}
+}
+
+// originOf returns the origin a received error was sent with or the name set with WithOrigin()
+func (o *options) originOf(err error) string {
+	if name := Origin(err); name != "" {
+		return name
+	}
+	return o.origin
+}
+
+// WithTimestamp includes when the error was sent in the ErrorType detail
+//
+// Timestamps are left out by default; they make every status unique, which
+// gets in the way of comparing and caching them.
+func WithTimestamp() Option {
+	return func(o *options) {
+		o.stamp = true
+	}
+}
+
+// WithMasking replaces the messages of Unknown, Internal and DataLoss errors with their public message
+//
+// Errors without a public message use the status text of their HTTP code.
+// Masking keeps the details of server faults, which are often driver or
+// library errors, from leaking to clients. Codes and attached details are
+// still sent. Problem details, JSON:API and GraphQL errors never include the
+// messages of errors, so masking is not needed for them.
+func WithMasking() Option {
+	return func(o *options) {
+		o.mask = true
+	}
+}
+
+// WithDefaultType sets the Error whose codes are used for errors that are missing them
+//
+// Errors without codes are sent as ErrUnknown unless they are classified
+// context errors. Use WithDefaultType(ErrInternal) for example to send them
+// as codes.Internal instead.
+func WithDefaultType(errType Error) Option {
+	return func(o *options) {
+		o.defaultType = &errType
+	}
+}
+
+// codes returns the GRPC, HTTP and Type codes of the error; missing codes come from the default type
+func (o *options) codes(err error) (codes.Code, int, string) {
+	defaultType := ErrUnknown
+	if o.defaultType != nil {
+		defaultType = *o.defaultType
+	}
+	if ce, ok := classify(err); ok {
+		defaultType = ce
+	}
+
+	grpcCode := defaultType.GRPCCode()
+	var grpcCoder GRPCCoder
+	if stderrors.As(err, &grpcCoder) {
+		grpcCode = grpcCoder.GRPCCode()
+	}
+
+	httpCode := defaultType.HTTPCode()
+	var httpCoder HTTPCoder
+	if stderrors.As(err, &httpCoder) {
+		httpCode = httpCoder.HTTPCode()
+	}
+
+	typeCode := defaultType.TypeCode()
+	var typeCoder TypeCoder
+	if stderrors.As(err, &typeCoder) {
+		typeCode = typeCoder.TypeCode()
+	}
+
+	return grpcCode, httpCode, typeCode
+}
+
+// WithCauseChain sends every coded layer of the error instead of only the outermost codes
+//
+// Each layer is sent as an ErrorType detail with its own codes and message.
+// The causes are sent innermost first so that receivers which only read the
+// last ErrorType detail continue to see the outermost codes.
+func WithCauseChain() Option {
+	return func(o *options) {
+		o.chain = true
+	}
+}
+
+// WithLogHook sets a function that is called with every error returned by a handler before it is converted
+func WithLogHook(hook func(ctx context.Context, err error)) Option {
+	return func(o *options) {
+		o.logHook = hook
+	}
+}
+
+func (o *options) log(ctx context.Context, err error) {
+	if o.logHook != nil {
+		o.logHook(ctx, err)
+	}
+}
+
+// WithIncomingContext reads the preferred locales from the "accept-language" incoming GRPC metadata
+//
+// The "grpcgateway-accept-language" key set by grpc-gateway is also checked.
+func WithIncomingContext(ctx context.Context) Option {
+	return func(o *options) {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return
+		}
+		for _, key := range []string{"accept-language", "grpcgateway-accept-language"} {
+			for _, value := range md.Get(key) {
+				o.locales = append(o.locales, parseAcceptLanguage(value)...)
+			}
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details documents
+const ProblemContentType = "application/problem+json"
+
+// extension members written by NewProblem(); fields with these names are left out
+const (
+	problemCode       = "code"
+	problemRetryable  = "retryable"
+	problemRetryDelay = "retryDelay"
+	problemHelp       = "help"
+	problemDetails    = "details"
+	problemOrigin     = "origin"
+	problemViolations = "invalid-params"
+)
+
+// Problem is an RFC 9457 problem details document
+//
+// Members other than the five defined by the RFC are kept in Extensions.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+type problemMembers struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+var problemMemberNames = map[string]bool{"type": true, "title": true, "status": true, "detail": true, "instance": true}
+
+var problemExtensionNames = map[string]bool{problemCode: true, problemRetryable: true, problemRetryDelay: true, problemHelp: true, problemDetails: true, problemOrigin: true, problemViolations: true}
+
+// MarshalJSON writes the extension members alongside the members defined by the RFC
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := map[string]interface{}{}
+	for name, value := range p.Extensions {
+		if !problemMemberNames[name] {
+			members[name] = value
+		}
+	}
+	defined, err := json.Marshal(problemMembers{Type: p.Type, Title: p.Title, Status: p.Status, Detail: p.Detail, Instance: p.Instance})
+	if err != nil {
+		return nil, err
+	}
+	var definedMembers map[string]interface{}
+	if err = json.Unmarshal(defined, &definedMembers); err != nil {
+		return nil, err
+	}
+	for name, value := range definedMembers {
+		members[name] = value
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON reads the members defined by the RFC and keeps every other member in Extensions
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var defined problemMembers
+	if err := json.Unmarshal(data, &defined); err != nil {
+		return err
+	}
+	var members map[string]interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	*p = Problem{Type: defined.Type, Title: defined.Title, Status: defined.Status, Detail: defined.Detail, Instance: defined.Instance}
+	for name, value := range members {
+		if problemMemberNames[name] {
+			continue
+		}
+		if p.Extensions == nil {
+			p.Extensions = map[string]interface{}{}
+		}
+		p.Extensions[name] = value
+	}
+	return nil
+}
+
+// NewProblem returns the problem details document for the error
+//
+//   - status is the HTTP code of the error
+//   - type is the URL of the first help link, or "about:blank" when there is none
+//   - title is the status text of the HTTP code
+//   - detail is the public message; otherwise the localized message that best
+//     matches the locales given with WithLocale(), or the status text. The
+//     message of the error is never used as it may reveal server internals.
+//   - instance is the ID of the error
+//
+// The type code is added as the "code" extension member. Fields attached to
+// the error, retry hints, field violations as "invalid-params", help links and
+// the details of registered error types are added as extension members too.
+// If err is nil then NewProblem returns nil.
+func NewProblem(err error, opts ...Option) *Problem {
+	if err == nil {
+		return nil
+	}
+
+	o := newOptions(opts)
+
+	_, httpCode, typeCode := o.codes(err)
+
+	p := &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(httpCode),
+		Status:   httpCode,
+		Detail:   o.publicDetail(err, httpCode),
+		Instance: ID(err),
+	}
+
+	extensions := map[string]interface{}{problemCode: typeCode}
+	for key, value := range Fields(err) {
+		if !problemMemberNames[key] && !problemExtensionNames[key] {
+			extensions[key] = value
+		}
+	}
+	if hint, ok := lookup[retryable](err); ok {
+		extensions[problemRetryable] = bool(hint)
+	}
+	if delay, ok := RetryDelay(err); ok {
+		extensions[problemRetryDelay] = delay.String()
+	}
+	if name := o.originOf(err); name != "" {
+		extensions[problemOrigin] = name
+	}
+	if violations := FieldViolations(err); len(violations) != 0 {
+		params := make([]map[string]string, len(violations))
+		for i, v := range violations {
+			params[i] = map[string]string{"name": v.Field, "reason": v.Description}
+		}
+		extensions[problemViolations] = params
+	}
+	if links := HelpLinks(err); len(links) != 0 {
+		p.Type = links[0].URL
+		help := make([]map[string]string, len(links))
+		for i, link := range links {
+			help[i] = map[string]string{"description": link.Description, "url": link.URL}
+		}
+		extensions[problemHelp] = help
+	}
+	var details []json.RawMessage
+	for _, detail := range append(encodeDetails(err), o.encodeDetails(err)...) {
+		a, err := anypb.New(protoadapt.MessageV2Of(detail))
+		if err != nil {
+			continue
+		}
+		data, err := protojson.Marshal(a)
+		if err != nil {
+			continue
+		}
+		details = append(details, data)
+	}
+	if len(details) != 0 {
+		extensions[problemDetails] = details
+	}
+	p.Extensions = extensions
+
+	return p
+}
+
+// TypeCode returns the type code written as the "code" extension member or blank when there is none
+func (p *Problem) TypeCode() string {
+	if p == nil {
+		return ""
+	}
+	typeCode, _ := p.Extensions[problemCode].(string)
+	return typeCode
+}
+
+// publicDetail returns the message the renderers write for clients
+//
+// This is the public message, the best localized message or the status text of
+// the HTTP code. The message of the error is never used as it may reveal server
+// internals.
+func (o *options) publicDetail(err error, httpCode int) string {
+	if message := PublicMessage(err); message != "" {
+		return message
+	}
+	if localized, ok := Localized(err, o.locales...); ok {
+		return localized.Message
+	}
+	return http.StatusText(httpCode)
+}
+
+// WriteProblem writes the error as an application/problem+json response
+//
+// The response is built with NewProblem() using the locales from the
+// Accept-Language header of the request. Options such as WithOrigin() are
+// applied. Retry-After and RateLimit headers are set with SetRetryHeaders().
+// Bodies are not written for HEAD requests.
+// If err is nil then nothing is written.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error, opts ...Option) {
+	writeProblem(w, r, err, ProblemContentType, encodeProblemJSON, opts)
+}
+
+// writeProblem writes the problem for the error with encode as a response of the media type
+func writeProblem(w http.ResponseWriter, r *http.Request, err error, contentType string, encode Encoder, opts []Option) {
+	if err == nil {
+		return
+	}
+	if r != nil {
+		if accept := r.Header.Get("Accept-Language"); accept != "" {
+			opts = append([]Option{WithLocale(parseAcceptLanguage(accept)...)}, opts...)
+		}
+	}
+
+	p := NewProblem(err, opts...)
+	var body bytes.Buffer
+	if encodeErr := encode(&body, p); encodeErr != nil {
+		http.Error(w, http.StatusText(p.Status), p.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set(TypeCodeHeader, p.TypeCode())
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	SetRetryHeaders(w.Header(), err)
+	w.WriteHeader(p.Status)
+	if r != nil && r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(body.Bytes())
+}
+
+// ParseProblem reads a problem details document
+func ParseProblem(r io.Reader) (*Problem, error) {
+	p := &Problem{}
+	if err := json.NewDecoder(r).Decode(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Err converts the problem back into a coded error
+//
+// The "code" extension member is used as the type code. Problems without one
+// are given the type code of the Error for their HTTP status. The result supports the same
+// functions as errors received over GRPC, including errors.Is(), ID(),
+// Fields(), HelpLinks() and errors.As() for registered error types.
+// If p is nil then Err returns nil.
+func (p *Problem) Err() error {
+	if p == nil {
+		return nil
+	}
+	return p.err("", newOptions(nil))
+}
+
+// err converts the problem using typeCode when the problem has no "code" member
+func (p *Problem) err(typeCode string, o *options) *grpcError {
+	httpCode := p.Status
+	if httpCode == 0 {
+		httpCode = http.StatusInternalServerError
+	}
+	if code := p.TypeCode(); code != "" {
+		typeCode = code
+	}
+
+	message := p.Detail
+	if message == "" {
+		message = p.Title
+	}
+
+	var details []interface{}
+	var help []HelpLink
+	var decoded []error
+
+	if p.Instance != "" {
+		details = append(details, errorID(p.Instance))
+	}
+
+	for name, value := range p.Extensions {
+		switch name {
+		case problemCode:
+			// read with TypeCode()
+		case problemRetryable:
+			if hint, ok := value.(bool); ok {
+				details = append(details, retryable(hint))
+			}
+		case problemRetryDelay:
+			if s, ok := value.(string); ok {
+				if delay, err := time.ParseDuration(s); err == nil {
+					details = append(details, retryDelay(delay))
+				}
+			}
+		case problemOrigin:
+			if s, ok := value.(string); ok {
+				details = append(details, origin(s))
+			}
+		case problemHelp:
+			links, _ := value.([]interface{})
+			for _, link := range links {
+				l, _ := link.(map[string]interface{})
+				url, _ := l["url"].(string)
+				description, _ := l["description"].(string)
+				if url != "" {
+					help = append(help, HelpLink{Description: description, URL: url})
+				}
+			}
+		case problemViolations:
+			params, _ := value.([]interface{})
+			for _, param := range params {
+				v, _ := param.(map[string]interface{})
+				name, _ := v["name"].(string)
+				reason, _ := v["reason"].(string)
+				details = append(details, FieldViolation{Field: name, Description: reason})
+			}
+		case problemDetails:
+			items, _ := value.([]interface{})
+			for _, item := range items {
+				if err, ok := decodeProblemDetail(item); ok {
+					decoded = append(decoded, err)
+				}
+			}
+		default:
+			if s, ok := value.(string); ok {
+				details = append(details, field{key: name, value: s})
+			}
+		}
+	}
+
+	if len(help) == 0 && p.Type != "" && p.Type != "about:blank" {
+		help = append(help, HelpLink{URL: p.Type})
+	}
+
+	e := httpError(typeCode, httpCode, message, o)
+	e.help = help
+	e.details = details
+	e.decoded = decoded
+	return e
+}
+
+// decodeProblemDetail rebuilds the registered error type for a detail written as JSON
+func decodeProblemDetail(item interface{}) (error, bool) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, false
+	}
+	a := &anypb.Any{}
+	if err = protojson.Unmarshal(data, a); err != nil {
+		return nil, false
+	}
+	m, err := a.UnmarshalNew()
+	if err != nil {
+		return nil, false
+	}
+	return decodeDetail(m)
+}
@@ -0,0 +1,93 @@
+package errors
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// headers written for errors that ask the client to slow down or come back later
+const (
+	RetryAfterHeader         = "Retry-After"
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+)
+
+// RateLimit is the quota of a client at the time of the error
+type RateLimit struct {
+	Limit     int           // requests allowed in the current window
+	Remaining int           // requests left in the current window
+	Reset     time.Duration // time until the window is reset
+}
+
+// WithRateLimit attaches the quota of the client to the error
+// If err is nil then WithRateLimit returns nil
+func WithRateLimit(err error, limit, remaining int, reset time.Duration) error {
+	return attach(err, RateLimit{Limit: limit, Remaining: remaining, Reset: reset})
+}
+
+// Quota returns the rate limit attached to or received with the error
+func Quota(err error) (RateLimit, bool) {
+	return lookup[RateLimit](err)
+}
+
+// SetRetryHeaders sets the Retry-After and RateLimit headers for the error
+//
+// Headers are only set for errors with the GRPC codes ResourceExhausted or
+// Unavailable, e.g. ErrTooManyRequests and ErrServiceUnavailable. Retry-After
+// is the retry delay of the error, or the time until an exhausted quota is
+// reset. The RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers
+// are set from the quota attached with WithRateLimit().
+// WriteProblem() and Handler() set these headers for you.
+func SetRetryHeaders(h http.Header, err error) {
+	switch GRPCCode(err) {
+	case codes.ResourceExhausted, codes.Unavailable:
+	default:
+		return
+	}
+
+	quota, hasQuota := Quota(err)
+	if delay, ok := RetryDelay(err); ok {
+		h.Set(RetryAfterHeader, seconds(delay))
+	} else if hasQuota && quota.Remaining <= 0 {
+		h.Set(RetryAfterHeader, seconds(quota.Reset))
+	}
+	if hasQuota {
+		h.Set(RateLimitLimitHeader, strconv.Itoa(quota.Limit))
+		h.Set(RateLimitRemainingHeader, strconv.Itoa(quota.Remaining))
+		h.Set(RateLimitResetHeader, seconds(quota.Reset))
+	}
+}
+
+// seconds returns the duration in whole seconds, rounded up so clients never retry early
+func seconds(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// retryHeaderDetails returns the retry delay and rate limit sent in the response headers
+func retryHeaderDetails(h http.Header) []interface{} {
+	var details []interface{}
+	if value := h.Get(RetryAfterHeader); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			details = append(details, retryDelay(time.Duration(n)*time.Second))
+		} else if t, err := http.ParseTime(value); err == nil {
+			details = append(details, retryDelay(max(time.Until(t), 0)))
+		}
+	}
+
+	limit, limitErr := strconv.Atoi(h.Get(RateLimitLimitHeader))
+	remaining, remainingErr := strconv.Atoi(h.Get(RateLimitRemainingHeader))
+	// a quota is only known when both the limit and what remains of it were sent
+	if limitErr == nil && remainingErr == nil {
+		reset, _ := strconv.Atoi(h.Get(RateLimitResetHeader))
+		details = append(details, RateLimit{Limit: limit, Remaining: remaining, Reset: time.Duration(reset) * time.Second})
+	}
+	return details
+}
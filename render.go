@@ -0,0 +1,340 @@
+package errors
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// media types written by NegotiatedRenderer()
+const (
+	ProblemXMLContentType = "application/problem+xml"
+	JSONContentType       = "application/json"
+	XMLContentType        = "application/xml"
+	HTMLContentType       = "text/html"
+	TextContentType       = "text/plain"
+)
+
+// Encoder writes a problem details document in a media type
+type Encoder func(w io.Writer, p *Problem) error
+
+type mediaType struct {
+	name   string
+	encode Encoder
+}
+
+// WithMediaType adds a media type that NegotiatedRenderer() may choose
+//
+// Media types added this way are preferred over the built in types when the
+// client accepts both equally, and replace a built in type with the same name.
+func WithMediaType(name string, encode Encoder) Option {
+	return func(o *options) {
+		o.mediaTypes = append(o.mediaTypes, mediaType{name: name, encode: encode})
+	}
+}
+
+// WithHTMLTemplate sets the template NegotiatedRenderer() uses for HTML error pages
+//
+// The template is executed with the *Problem for the error.
+func WithHTMLTemplate(t *template.Template) Option {
+	return func(o *options) {
+		o.html = t
+	}
+}
+
+// NegotiatedRenderer returns a Renderer that writes errors in the media type that best matches the Accept header
+//
+// The problem details document from NewProblem() is written as
+// application/problem+json, application/json, application/problem+xml,
+// application/xml, text/plain or an HTML page. Clients that do not send an
+// Accept header, or accept none of the media types, are sent
+// application/problem+json. Add media types with WithMediaType() and replace
+// the HTML page with WithHTMLTemplate(). The options also apply to the
+// problem, so WithLocale() may be used.
+func NegotiatedRenderer(opts ...Option) Renderer {
+	o := newOptions(opts)
+
+	html := o.html
+	if html == nil {
+		html = defaultHTMLTemplate
+	}
+
+	offers := append([]mediaType{}, o.mediaTypes...)
+	offers = append(offers,
+		mediaType{name: ProblemContentType, encode: encodeProblemJSON},
+		mediaType{name: JSONContentType, encode: encodeProblemJSON},
+		mediaType{name: ProblemXMLContentType, encode: encodeProblemXML},
+		mediaType{name: XMLContentType, encode: encodeProblemXML},
+		mediaType{name: HTMLContentType, encode: func(w io.Writer, p *Problem) error {
+			return html.Execute(w, p)
+		}},
+		mediaType{name: TextContentType, encode: encodeProblemText},
+	)
+
+	// the first offer for problem+json is sent to clients that accept nothing else
+	var fallback mediaType
+	for _, offer := range offers {
+		if offer.name == ProblemContentType {
+			fallback = offer
+			break
+		}
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		offer, ok := mediaType{}, false
+		if r != nil {
+			offer, ok = negotiate(r.Header.Get("Accept"), offers)
+		}
+		if !ok {
+			offer = fallback
+		}
+		contentType := offer.name
+		if strings.HasPrefix(contentType, "text/") {
+			contentType += "; charset=utf-8"
+		}
+		w.Header().Add("Vary", "Accept")
+		writeProblem(w, r, err, contentType, offer.encode, opts)
+	}
+}
+
+// negotiate returns the offer with the highest quality in the Accept value; earlier offers win ties
+func negotiate(accept string, offers []mediaType) (mediaType, bool) {
+	ranges := parseAccept(accept)
+
+	var best mediaType
+	var bestQ float64
+	for _, offer := range offers {
+		if q := acceptQuality(ranges, offer.name); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept returns the media ranges of an Accept value
+func parseAccept(value string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(value, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: name, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality of the most specific range matching the media type
+func acceptQuality(ranges []acceptRange, name string) float64 {
+	name, _, _ = mime.ParseMediaType(name)
+	group, _, _ := strings.Cut(name, "/")
+
+	q, specificity := 0.0, 0
+	for _, r := range ranges {
+		var s int
+		switch r.mediaType {
+		case name:
+			s = 3
+		case group + "/*":
+			s = 2
+		case "*/*":
+			s = 1
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+func encodeProblemJSON(w io.Writer, p *Problem) error {
+	return json.NewEncoder(w).Encode(p)
+}
+
+// problemXMLNamespace is the namespace of problem details documents written as XML; see RFC 9457 Appendix B
+const problemXMLNamespace = "urn:ietf:rfc:7807"
+
+// problemXML is the XML format of problem details documents from RFC 9457
+//
+// Extension members are written as elements after the members defined by the
+// RFC: objects as elements with an element for each member and arrays as
+// elements with an "i" element for each item. Members whose names are not
+// valid XML names are left out.
+type problemXML struct {
+	*Problem
+}
+
+func (p problemXML) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Space: problemXMLNamespace, Local: "problem"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	members := map[string]interface{}{}
+	if p.Extensions != nil {
+		// extensions are written as they would be in JSON
+		data, err := json.Marshal(p.Extensions)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(data, &members); err != nil {
+			return err
+		}
+	}
+	if p.Type != "" {
+		members["type"] = p.Type
+	}
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = float64(p.Status)
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	// the members defined by the RFC come first, in the order they are defined
+	for _, name := range []string{"type", "title", "status", "detail", "instance"} {
+		if value, ok := members[name]; ok {
+			if err := encodeXMLMember(e, name, value); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range sortedNames(members) {
+		if problemMemberNames[name] {
+			continue
+		}
+		if err := encodeXMLMember(e, name, members[name]); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// encodeXMLMember writes the JSON value as an element with the name
+func encodeXMLMember(e *xml.Encoder, name string, value interface{}) error {
+	if value == nil || !xmlName(name) {
+		return nil
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, name := range sortedNames(v) {
+			if err := encodeXMLMember(e, name, v[name]); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	case []interface{}:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, item := range v {
+			if err := encodeXMLMember(e, "i", item); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	case float64:
+		return e.EncodeElement(strconv.FormatFloat(v, 'f', -1, 64), start)
+	default:
+		return e.EncodeElement(fmt.Sprint(v), start)
+	}
+}
+
+// xmlName returns true if name can be used as the name of an element
+func xmlName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func sortedNames(members map[string]interface{}) []string {
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func encodeProblemXML(w io.Writer, p *Problem) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(problemXML{p})
+}
+
+// encodeProblemText writes the type code, or the title when there is none, and the detail on a single line
+func encodeProblemText(w io.Writer, p *Problem) error {
+	name := p.TypeCode()
+	if name == "" {
+		name = p.Title
+	}
+	_, err := fmt.Fprintf(w, "%s: %s\n", name, p.Detail)
+	return err
+}
+
+var defaultHTMLTemplate = template.Must(template.New("problem").Funcs(template.FuncMap{
+	"statusText": http.StatusText,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Status}} {{statusText .Status}}</title>
+</head>
+<body>
+<h1>{{.Status}} {{statusText .Status}}</h1>
+<p>{{.Detail}}</p>
+{{- if .Instance}}
+<p>Reference: <code>{{.Instance}}</code></p>
+{{- end}}
+{{- if ne .Type "about:blank"}}
+<p><a href="{{.Type}}">More information</a></p>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,126 @@
+package errors
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// RetryPolicy decides how the retrying client interceptors retry failed calls
+//
+// Zero values are replaced by the defaults noted on each field.
+type RetryPolicy struct {
+	MaxAttempts    int                  // attempts made including the first; 3 by default
+	InitialBackoff time.Duration        // wait before the first retry; 100ms by default
+	MaxBackoff     time.Duration        // longest wait between attempts; 5s by default
+	Multiplier     float64              // growth of the wait after every attempt; 2 by default
+	Jitter         float64              // fraction, 0 to 1, the wait is randomly changed by; 0.2 by default
+	Retryable      func(err error) bool // returns true if the received error may be retried; Retryable() by default
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 5 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	if p.Jitter <= 0 || p.Jitter > 1 {
+		p.Jitter = 0.2
+	}
+	if p.Retryable == nil {
+		p.Retryable = Retryable
+	}
+	return p
+}
+
+// backoff returns how long to wait after the attempt failed with err
+//
+// Retry delays sent by the server are used as they are; otherwise the
+// backoff grows exponentially from InitialBackoff and is jittered.
+func (p RetryPolicy) backoff(attempt int, err error) time.Duration {
+	if delay, ok := RetryDelay(err); ok {
+		return delay
+	}
+	wait := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	wait = math.Min(wait, float64(p.MaxBackoff))
+	wait *= 1 + p.Jitter*(2*rand.Float64()-1)
+	return time.Duration(wait)
+}
+
+// UnaryClientRetryInterceptor returns an interceptor that receives errors like UnaryClientInterceptor and retries calls that failed with retryable errors
+//
+// Errors are retried when Retryable() reports true for them: errors sent with
+// WithRetryable() or WithRetryDelay() hints, or with the type codes of
+// ErrAborted, ErrResourceExhausted, ErrUnavailable, ErrServiceUnavailable or
+// ErrTooManyRequests. Retry delays sent by the server are honored. No retry is
+// made when the wait would pass the deadline of the call's context. The last
+// received error is returned.
+func UnaryClientRetryInterceptor(policy RetryPolicy, options ...Option) grpc.UnaryClientInterceptor {
+	policy = policy.withDefaults()
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		receiveOpts := withCall(cc, method, options)
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil {
+				return nil
+			}
+			err = ReceiveGRPCError(err, receiveOpts...)
+			if !policy.retry(ctx, attempt, err) {
+				return err
+			}
+		}
+	}
+}
+
+// StreamClientRetryInterceptor returns an interceptor that receives errors like StreamClientInterceptor and retries failures to create a stream
+//
+// Only the creation of the stream is retried, using the same rules as
+// UnaryClientRetryInterceptor(). Errors returned mid-stream are received but
+// never retried.
+func StreamClientRetryInterceptor(policy RetryPolicy, options ...Option) grpc.StreamClientInterceptor {
+	policy = policy.withDefaults()
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		receiveOpts := withCall(cc, method, options)
+		for attempt := 1; ; attempt++ {
+			stream, err := streamer(ctx, desc, cc, method, opts...)
+			if err == nil {
+				return &clientStream{ClientStream: stream, options: receiveOpts}, nil
+			}
+			err = ReceiveGRPCError(err, receiveOpts...)
+			if !policy.retry(ctx, attempt, err) {
+				return nil, err
+			}
+		}
+	}
+}
+
+// retry waits before the next attempt and returns true, or returns false when there should not be one
+func (p RetryPolicy) retry(ctx context.Context, attempt int, err error) bool {
+	if attempt >= p.MaxAttempts || !p.Retryable(err) {
+		return false
+	}
+
+	wait := p.backoff(attempt, err)
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		return false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
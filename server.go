@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// UnaryServerInterceptor returns an interceptor that sends every error returned by a handler with SendGRPCError
+//
+// The locales requested by the client are used to pick localized messages.
+// Use WithLogHook() to log errors and WithMasking() to hide the messages of
+// server faults from clients.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			o.log(ctx, err)
+			err = SendGRPCError(err, append([]Option{WithIncomingContext(ctx)}, opts...)...)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that sends every error returned by a handler with SendGRPCError
+//
+// The options are the same as for UnaryServerInterceptor.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err != nil {
+			ctx := ss.Context()
+			o.log(ctx, err)
+			err = SendGRPCError(err, append([]Option{WithIncomingContext(ctx)}, opts...)...)
+		}
+		return err
+	}
+}
+
+// codes for problems on the server side whose messages are masked
+func isServerFault(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// maskedMessage returns the public message of the error or the status text of the HTTP code
+func maskedMessage(err error, httpCode int) string {
+	if message := PublicMessage(err); message != "" {
+		return message
+	}
+	if text := http.StatusText(httpCode); text != "" {
+		return text
+	}
+	return http.StatusText(http.StatusInternalServerError)
+}
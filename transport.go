@@ -0,0 +1,77 @@
+package errors
+
+import (
+	stderrors "errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Transport is an http.RoundTripper that returns coded errors for requests that get no response
+//
+// Requests that fail in the transport are given codes: timeouts become
+// ErrDeadlineExceeded, canceled requests ErrCanceled, and network failures
+// such as dial errors and dropped connections ErrUnavailable.
+//
+// Responses are returned as they are, including those with an error status,
+// as every http.RoundTripper must. Send requests with Do() to also get coded
+// errors for error statuses, or use ReceiveHTTPError() on the response.
+//
+//	client := &http.Client{Transport: errors.Transport{Base: http.DefaultTransport}}
+type Transport struct {
+	// Base sends the requests; http.DefaultTransport is used when nil
+	Base http.RoundTripper
+}
+
+// RoundTrip sends the request with the Base transport and returns a coded error when there is no response
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, transportError(err)
+	}
+	return resp, nil
+}
+
+// Do sends the request with the client and returns a coded error for responses with an error status
+//
+// Error statuses are received with ReceiveHTTPError() and the options are
+// passed to it. The response is returned with the error so that its body
+// can still be read; the caller must close it. Requests that get no
+// response are coded as they are by Transport.
+// If client is nil then http.DefaultClient is used.
+func Do(client *http.Client, req *http.Request, opts ...Option) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var coder GRPCCoder
+		if stderrors.As(err, &coder) {
+			return nil, err
+		}
+		return nil, transportError(err)
+	}
+	return resp, ReceiveHTTPError(resp, opts...)
+}
+
+// transportError returns the error with the codes for a request that did not get a response
+func transportError(err error) error {
+	if ce, ok := contextError(err); ok {
+		return ce.Err(err)
+	}
+	var netErr net.Error
+	if stderrors.As(err, &netErr) && netErr.Timeout() {
+		return ErrDeadlineExceeded.Err(err)
+	}
+	var opErr *net.OpError
+	if stderrors.As(err, &opErr) || stderrors.Is(err, io.EOF) || stderrors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrUnavailable.Err(err)
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package errors
+
+import (
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+// HTTPCodePolicy decides what happens to received HTTP codes that are not errors
+type HTTPCodePolicy int
+
+const (
+	// TrustHTTPCode uses every received HTTP code as it was sent. This is the default.
+	TrustHTTPCode HTTPCodePolicy = iota
+	// RejectHTTPCode replaces HTTP codes outside 400-599 with the HTTP code for the status code
+	RejectHTTPCode
+	// ClampHTTPCode moves HTTP codes below 400 up to 400 and those above 599 down to 599
+	ClampHTTPCode
+)
+
+// Reasons given for a Mismatch
+const (
+	MismatchHTTPCode = "http code out of range"
+	MismatchGRPCCode = "grpc code differs from status"
+)
+
+// Mismatch describes a received ErrorType detail that contradicts the status or the TrustPolicy
+type Mismatch struct {
+	Target     string     // target of the client connection when known
+	Method     string     // full method name of the call when known, e.g. "/pkg.Service/Method"
+	Reason     string     // MismatchHTTPCode or MismatchGRPCCode
+	StatusCode codes.Code // code of the received status
+	TypeCode   string     // type code claimed by the detail
+	HTTPCode   int        // HTTP code claimed by the detail
+	GRPCCode   codes.Code // GRPC code claimed by the detail
+}
+
+// TrustPolicy decides how much of the ErrorType details received from a server are believed
+//
+// The zero value trusts every detail.
+type TrustPolicy struct {
+	// AcceptDetails returns true if the details sent by the peer are to be read; nil accepts all.
+	// Errors from peers that are not accepted are received with only the status code and message.
+	AcceptDetails func(target, method string) bool
+	// HTTPCodes decides what happens to HTTP codes outside 400-599
+	HTTPCodes HTTPCodePolicy
+	// VerifyGRPCCode ignores every ErrorType detail when the outermost claims a different GRPC code than the status
+	VerifyGRPCCode bool
+	// OnMismatch is called for every detail that is rejected or altered by the policy
+	OnMismatch func(mismatch Mismatch)
+}
+
+var trust struct {
+	sync.RWMutex
+	policy TrustPolicy
+}
+
+// SetTrustPolicy sets the policy used when receiving errors without WithTrustPolicy()
+func SetTrustPolicy(policy TrustPolicy) {
+	trust.Lock()
+	defer trust.Unlock()
+	trust.policy = policy
+}
+
+// WithTrustPolicy overrides SetTrustPolicy() for the errors being received
+func WithTrustPolicy(policy TrustPolicy) Option {
+	return func(o *options) {
+		o.trust = &policy
+	}
+}
+
+// WithPeer sets the target and full method name of the call the error was received from
+//
+// The client interceptors set the peer for every call.
+func WithPeer(target, method string) Option {
+	return func(o *options) {
+		o.target = target
+		o.method = method
+	}
+}
+
+func (o *options) trustPolicy() TrustPolicy {
+	if o.trust != nil {
+		return *o.trust
+	}
+	trust.RLock()
+	defer trust.RUnlock()
+	return trust.policy
+}
+
+// acceptsDetails returns true if details from the peer are to be read
+func (o *options) acceptsDetails() bool {
+	policy := o.trustPolicy()
+	return policy.AcceptDetails == nil || policy.AcceptDetails(o.target, o.method)
+}
+
+// trustsErrorTypes returns false when the outermost ErrorType claims a different GRPC code than the status
+func (o *options) trustsErrorTypes(statusCode codes.Code, details []interface{}) bool {
+	policy := o.trustPolicy()
+	if !policy.VerifyGRPCCode {
+		return true
+	}
+	for i := len(details) - 1; i >= 0; i-- {
+		errType, ok := details[i].(*ErrorType)
+		if !ok {
+			continue
+		}
+		if codes.Code(errType.GetGRPCCode()) == statusCode {
+			return true
+		}
+		o.mismatch(policy, MismatchGRPCCode, statusCode, errType)
+		return false
+	}
+	return true
+}
+
+// checkHTTPCode applies the HTTP code policy to the ErrorType
+func (o *options) checkHTTPCode(statusCode codes.Code, errType *ErrorType) {
+	policy := o.trustPolicy()
+	httpCode := errType.GetHTTPCode()
+	if policy.HTTPCodes == TrustHTTPCode || (httpCode >= 400 && httpCode <= 599) {
+		return
+	}
+	o.mismatch(policy, MismatchHTTPCode, statusCode, errType)
+	switch {
+	case policy.HTTPCodes == RejectHTTPCode:
+		errType.HTTPCode = int64(codeToError(statusCode).HTTPCode())
+	case httpCode < 400:
+		errType.HTTPCode = 400
+	default:
+		errType.HTTPCode = 599
+	}
+}
+
+func (o *options) mismatch(policy TrustPolicy, reason string, statusCode codes.Code, errType *ErrorType) {
+	if policy.OnMismatch == nil {
+		return
+	}
+	policy.OnMismatch(Mismatch{
+		Target:     o.target,
+		Method:     o.method,
+		Reason:     reason,
+		StatusCode: statusCode,
+		TypeCode:   errType.GetTypeCode(),
+		HTTPCode:   int(errType.GetHTTPCode()),
+		GRPCCode:   codes.Code(errType.GetGRPCCode()),
+	})
+}
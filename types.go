@@ -23,21 +23,42 @@ const (
 
 // Errors named in line with HTTP statuses
 const (
-	ErrBadRequest                 Error = "BAD_REQUEST"                   // HTTP: 400 GRPC: codes.InvalidArgument
-	ErrUnauthorized               Error = "UNAUTHORIZED"                  // HTTP: 401 GRPC: codes.Unauthenticated
-	ErrForbidden                  Error = "FORBIDDEN"                     // HTTP: 403 GRPC: codes.PermissionDenied
-	ErrMethodNotAllowed           Error = "METHOD_NOT_ALLOWED"            // HTTP: 405 GRPC: codes.Unimplemented
-	ErrRequestTimeout             Error = "REQUEST_TIMEOUT"               // HTTP: 408 GRPC: codes.DeadlineExceeded
-	ErrConflict                   Error = "CONFLICT"                      // HTTP: 409 GRPC: codes.AlreadyExists
-	ErrGone                       Error = "GONE"                          // HTTP: 410 GRPC: codes.NotFound
-	ErrUnsupportedMediaType       Error = "UNSUPPORTED_MEDIA_TYPE"        // HTTP: 415 GRPC: codes.InvalidArgument
-	ErrImATeapot                  Error = "IM_A_TEAPOT"                   // HTTP: 418 GRPC: codes.Unknown
-	ErrUnprocessableEntity        Error = "UNPROCESSABLE_ENTITY"          // HTTP: 422 GRPC: codes.InvalidArgument
-	ErrTooManyRequests            Error = "TOO_MANY_REQUESTS"             // HTTP: 429 GRPC: codes.ResourceExhausted
-	ErrUnavailableForLegalReasons Error = "UNAVAILABLE_FOR_LEGAL_REASONS" // HTTP: 451 GRPC: codes.Unavailable
-	ErrInternalServerError        Error = "INTERNAL_SERVER_ERROR"         // HTTP: 500 GRPC: codes.Internal
-	ErrNotImplemented             Error = "NOT_IMPLEMENTED"               // HTTP: 501 GRPC: codes.Unimplemented
-	ErrBadGateway                 Error = "BAD_GATEWAY"                   // HTTP: 502 GRPC: codes.Aborted
-	ErrServiceUnavailable         Error = "SERVICE_UNAVAILABLE"           // HTTP: 503 GRPC: codes.Unavailable
-	ErrGatewayTimeout             Error = "GATEWAY_TIMEOUT"               // HTTP: 504 GRPC: codes.DeadlineExceeded
+	ErrBadRequest                    Error = "BAD_REQUEST"                     // HTTP: 400 GRPC: codes.InvalidArgument
+	ErrUnauthorized                  Error = "UNAUTHORIZED"                    // HTTP: 401 GRPC: codes.Unauthenticated
+	ErrPaymentRequired               Error = "PAYMENT_REQUIRED"                // HTTP: 402 GRPC: codes.FailedPrecondition
+	ErrForbidden                     Error = "FORBIDDEN"                       // HTTP: 403 GRPC: codes.PermissionDenied
+	ErrMethodNotAllowed              Error = "METHOD_NOT_ALLOWED"              // HTTP: 405 GRPC: codes.Unimplemented
+	ErrNotAcceptable                 Error = "NOT_ACCEPTABLE"                  // HTTP: 406 GRPC: codes.InvalidArgument
+	ErrProxyAuthRequired             Error = "PROXY_AUTH_REQUIRED"             // HTTP: 407 GRPC: codes.Unauthenticated
+	ErrRequestTimeout                Error = "REQUEST_TIMEOUT"                 // HTTP: 408 GRPC: codes.DeadlineExceeded
+	ErrConflict                      Error = "CONFLICT"                        // HTTP: 409 GRPC: codes.AlreadyExists
+	ErrGone                          Error = "GONE"                            // HTTP: 410 GRPC: codes.NotFound
+	ErrLengthRequired                Error = "LENGTH_REQUIRED"                 // HTTP: 411 GRPC: codes.InvalidArgument
+	ErrPreconditionFailed            Error = "PRECONDITION_FAILED"             // HTTP: 412 GRPC: codes.FailedPrecondition
+	ErrPayloadTooLarge               Error = "PAYLOAD_TOO_LARGE"               // HTTP: 413 GRPC: codes.InvalidArgument
+	ErrURITooLong                    Error = "URI_TOO_LONG"                    // HTTP: 414 GRPC: codes.InvalidArgument
+	ErrUnsupportedMediaType          Error = "UNSUPPORTED_MEDIA_TYPE"          // HTTP: 415 GRPC: codes.InvalidArgument
+	ErrRangeNotSatisfiable           Error = "RANGE_NOT_SATISFIABLE"           // HTTP: 416 GRPC: codes.OutOfRange
+	ErrExpectationFailed             Error = "EXPECTATION_FAILED"              // HTTP: 417 GRPC: codes.FailedPrecondition
+	ErrImATeapot                     Error = "IM_A_TEAPOT"                     // HTTP: 418 GRPC: codes.Unknown
+	ErrMisdirectedRequest            Error = "MISDIRECTED_REQUEST"             // HTTP: 421 GRPC: codes.Unavailable
+	ErrUnprocessableEntity           Error = "UNPROCESSABLE_ENTITY"            // HTTP: 422 GRPC: codes.InvalidArgument
+	ErrLocked                        Error = "LOCKED"                          // HTTP: 423 GRPC: codes.FailedPrecondition
+	ErrFailedDependency              Error = "FAILED_DEPENDENCY"               // HTTP: 424 GRPC: codes.FailedPrecondition
+	ErrTooEarly                      Error = "TOO_EARLY"                       // HTTP: 425 GRPC: codes.Unavailable
+	ErrUpgradeRequired               Error = "UPGRADE_REQUIRED"                // HTTP: 426 GRPC: codes.FailedPrecondition
+	ErrPreconditionRequired          Error = "PRECONDITION_REQUIRED"           // HTTP: 428 GRPC: codes.FailedPrecondition
+	ErrTooManyRequests               Error = "TOO_MANY_REQUESTS"               // HTTP: 429 GRPC: codes.ResourceExhausted
+	ErrRequestHeaderFieldsTooLarge   Error = "REQUEST_HEADER_FIELDS_TOO_LARGE" // HTTP: 431 GRPC: codes.InvalidArgument
+	ErrUnavailableForLegalReasons    Error = "UNAVAILABLE_FOR_LEGAL_REASONS"   // HTTP: 451 GRPC: codes.Unavailable
+	ErrInternalServerError           Error = "INTERNAL_SERVER_ERROR"           // HTTP: 500 GRPC: codes.Internal
+	ErrNotImplemented                Error = "NOT_IMPLEMENTED"                 // HTTP: 501 GRPC: codes.Unimplemented
+	ErrBadGateway                    Error = "BAD_GATEWAY"                     // HTTP: 502 GRPC: codes.Aborted
+	ErrServiceUnavailable            Error = "SERVICE_UNAVAILABLE"             // HTTP: 503 GRPC: codes.Unavailable
+	ErrGatewayTimeout                Error = "GATEWAY_TIMEOUT"                 // HTTP: 504 GRPC: codes.DeadlineExceeded
+	ErrHTTPVersionNotSupported       Error = "HTTP_VERSION_NOT_SUPPORTED"      // HTTP: 505 GRPC: codes.Unimplemented
+	ErrVariantAlsoNegotiates         Error = "VARIANT_ALSO_NEGOTIATES"         // HTTP: 506 GRPC: codes.Internal
+	ErrInsufficientStorage           Error = "INSUFFICIENT_STORAGE"            // HTTP: 507 GRPC: codes.ResourceExhausted
+	ErrLoopDetected                  Error = "LOOP_DETECTED"                   // HTTP: 508 GRPC: codes.Internal
+	ErrNetworkAuthenticationRequired Error = "NETWORK_AUTHENTICATION_REQUIRED" // HTTP: 511 GRPC: codes.Unauthenticated
 )
@@ -0,0 +1,42 @@
+package errors
+
+// FieldViolation describes a field of a request that is not valid
+type FieldViolation struct {
+	Field       string // path to the field, e.g. "user.email"
+	Description string // why the value is not valid
+}
+
+// WithFieldViolation attaches a description of why the value of the field is not valid
+//
+// Any number of violations may be attached. They are sent over GRPC as a
+// google.rpc.BadRequest detail.
+// If err is nil then WithFieldViolation returns nil
+func WithFieldViolation(err error, field, description string) error {
+	return attach(err, FieldViolation{Field: field, Description: description})
+}
+
+// FieldViolations returns every field violation attached to or received with the error in the order they were attached
+func FieldViolations(err error) []FieldViolation {
+	var violations []FieldViolation
+	walk(err, func(err error) bool {
+		switch e := err.(type) {
+		case detailedError:
+			if v, ok := e.d.(FieldViolation); ok {
+				violations = append(violations, v)
+			}
+		case *grpcError:
+			for i := len(e.details) - 1; i >= 0; i-- {
+				if v, ok := e.details[i].(FieldViolation); ok {
+					violations = append(violations, v)
+				}
+			}
+		}
+		return true
+	})
+
+	// outer details are visited first but were attached last
+	for i, j := 0, len(violations)-1; i < j; i, j = i+1, j-1 {
+		violations[i], violations[j] = violations[j], violations[i]
+	}
+	return violations
+}